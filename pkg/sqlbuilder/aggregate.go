@@ -0,0 +1,128 @@
+package sqlbuilder
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Count builds "count(expr)".
+func Count(expr Expression) Expression {
+	return Func("count", expr)
+}
+
+// CountAll builds "count(*)".
+func CountAll() Expression {
+	return Count(Ref("*"))
+}
+
+// CountDistinct builds "count(distinct expr)".
+func CountDistinct(expr Expression) Expression {
+	return FuncDistinct("count", expr)
+}
+
+// FuncDistinct is like Func but only passes distinct values to the aggregate
+// fn, building "fn(distinct a, b)".
+func FuncDistinct(fn string, args ...Expression) Expression {
+	return FuncExpr{Name: fn, Args: args, Distinct: true}
+}
+
+// Sum builds "sum(expr)".
+func Sum(expr Expression) Expression {
+	return Func("sum", expr)
+}
+
+// Avg builds "avg(expr)".
+func Avg(expr Expression) Expression {
+	return Func("avg", expr)
+}
+
+// Min builds "min(expr)".
+func Min(expr Expression) Expression {
+	return Func("min", expr)
+}
+
+// Max builds "max(expr)".
+func Max(expr Expression) Expression {
+	return Func("max", expr)
+}
+
+// Filter limits the rows agg aggregates to those matching predicates, building
+// "agg filter (where a and b)". Without any predicates it returns agg as it is.
+func Filter(agg Expression, predicates ...Expression) Expression {
+	if len(predicates) == 0 {
+		return agg
+	}
+
+	return FilterExpr{Agg: agg, Predicates: predicates}
+}
+
+// FuncOrdered is like Func but sorts the rows fed to the aggregate fn by
+// order, building "fn(a, b order by c, d)". Without any order it's the same as
+// Func.
+func FuncOrdered(fn string, args []Expression, order ...string) Expression {
+	return FuncExpr{Name: fn, Args: args, OrderBy: refs(order)}
+}
+
+// CountRows returns a statement counting the rows st returns, building
+// "select count(*) from (sub) as _count", for things like pagination totals.
+// The order by, limit and offset of st are left out of sub. When st selects
+// only plain columns, and isn't distinct, its select list is replaced with "1"
+// since it can't change the count. Any other select list, such as one with an
+// aggregate or a set returning function, is kept as it is. Grouped selects
+// count their groups. CountRows panics when st isn't a select or compound.
+func CountRows(st Statement) Statement {
+	if st.Kind != _SelectStatement && !st.isCompound() {
+		panic(fmt.Sprintf("sqlbuilder: can't count the rows of a %s statement", st.Kind))
+	}
+
+	sub := st.Clone()
+	sub.Clauses = sub.Clauses[:0]
+
+	for _, clause := range st.Clauses {
+		switch clause.Kind() {
+		case _OrderByClause:
+			// which row of each distinct on group is kept doesn't change
+			// the count, so the order by isn't needed to lead with them
+			sub.distinctOnUnchecked = true
+			continue
+		case _LimitClause, _OffsetClause:
+			continue
+		}
+
+		sub.Clauses = append(sub.Clauses, clause)
+	}
+
+	if sub.Kind == _SelectStatement && !sub.distinct && len(sub.distinctOn) == 0 && onlyColumns(sub.Expressions) {
+		sub.Expressions = []Expression{Ref("1")}
+	}
+
+	count := Select(CountAll(), FromSubselect(sub, "_count"))
+	count.err = st.err
+
+	return count
+}
+
+// onlyColumns reports whether exprs are all refs to columns, possibly aliased,
+// rather than calls or other expressions.
+func onlyColumns(exprs []Expression) bool {
+	for _, expr := range exprs {
+		switch e := expr.(type) {
+		case MultiExpression:
+			if !onlyColumns(e.Expressions) {
+				return false
+			}
+		case AliasExpr:
+			if !onlyColumns([]Expression{e.Expr}) {
+				return false
+			}
+		case RefExpr:
+			if strings.ContainsAny(e.Name, "() ") {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,262 @@
+package sqlbuilder
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestAggregates(t *testing.T) {
+	cases := []struct {
+		description string
+		expected    string
+		expr        Expression
+	}{
+		{
+			description: "count",
+			expected:    "count(id)",
+			expr:        Count(Ref("id")),
+		},
+		{
+			description: "count all with alias",
+			expected:    "count(*) as n",
+			expr:        As(CountAll(), "n"),
+		},
+		{
+			description: "count distinct",
+			expected:    "count(distinct user_id)",
+			expr:        CountDistinct(Ref("user_id")),
+		},
+		{
+			description: "sum",
+			expected:    "sum(total)",
+			expr:        Sum(Ref("total")),
+		},
+		{
+			description: "avg",
+			expected:    "avg(total) as mean",
+			expr:        As(Avg(Ref("total")), "mean"),
+		},
+		{
+			description: "min",
+			expected:    "min(created_at)",
+			expr:        Min(Ref("created_at")),
+		},
+		{
+			description: "max of an expression",
+			expected:    "max((price * qty))",
+			expr:        Max(Mul(Ref("price"), Ref("qty"))),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			is.Equal(c.expected, c.expr.Build())
+		})
+	}
+}
+
+func TestAggregateStatement(t *testing.T) {
+	is := is.New(t)
+
+	st := Select(
+		Columns(Ref("user_id"), As(Count(Ref("*")), "n")),
+		From(Ref("orders")),
+		GroupBy("user_id"),
+		Having(Greater(CountAll(), Placeholder())),
+	)
+
+	is.Equal("select user_id, count(*) as n from orders group by user_id having (count(*) > ?)", st.Build())
+}
+
+func TestFilter(t *testing.T) {
+	is := is.New(t)
+
+	sql, args := Select(
+		Columns(
+			As(Filter(Count(Ref("*")), Equals(Ref("active"), Arg(true))), "n"),
+			As(Filter(Sum(Ref("amount")), Equals(Ref("kind"), Arg("refund")), Greater(Ref("amount"), Arg(0))), "refunds"),
+		),
+		From(Ref("orders")),
+		Where(Equals(Ref("team"), Arg("a"))),
+	).BuildArgsWithDialect(Postgres)
+
+	is.Equal("select count(*) filter (where active = $1) as n, sum(amount) filter (where kind = $2 and amount > $3) as refunds from orders where (team = $4)", sql)
+	is.Equal([]interface{}{true, "refund", 0, "a"}, args)
+}
+
+func TestFilterWithoutPredicates(t *testing.T) {
+	is := is.New(t)
+
+	var predicates []Expression
+
+	is.Equal("count(*)", Filter(CountAll(), predicates...).Build())
+	is.Equal("count(*)", FilterExpr{Agg: CountAll()}.Build())
+}
+
+func TestFuncDistinct(t *testing.T) {
+	cases := []struct {
+		description string
+		expected    string
+		expr        Expression
+	}{
+		{
+			description: "single argument",
+			expected:    "array_agg(distinct tag)",
+			expr:        FuncDistinct("array_agg", Ref("tag")),
+		},
+		{
+			description: "many arguments",
+			expected:    "string_agg(distinct name, ',') as names",
+			expr:        As(FuncDistinct("string_agg", Ref("name"), Const(",")), "names"),
+		},
+		{
+			description: "placeholder",
+			expected:    "string_agg(distinct name, ?)",
+			expr:        FuncDistinct("string_agg", Ref("name"), Placeholder()),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			is.Equal(c.expected, c.expr.Build())
+		})
+	}
+}
+
+func TestFuncOrdered(t *testing.T) {
+	cases := []struct {
+		description string
+		expected    string
+		expr        Expression
+	}{
+		{
+			description: "single order",
+			expected:    "string_agg(name, ',' order by name)",
+			expr:        FuncOrdered("string_agg", []Expression{Ref("name"), Const(",")}, "name"),
+		},
+		{
+			description: "multiple orders",
+			expected:    "array_agg(id order by created_at, id) as ids",
+			expr:        As(FuncOrdered("array_agg", []Expression{Ref("id")}, "created_at", "id"), "ids"),
+		},
+		{
+			description: "no order",
+			expected:    "array_agg(id)",
+			expr:        FuncOrdered("array_agg", []Expression{Ref("id")}),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			is.Equal(c.expected, c.expr.Build())
+		})
+	}
+}
+
+func TestCountRows(t *testing.T) {
+	page := Select(Columns(Ref("id"), Ref("name")), From(Ref("users")),
+		Join(Ref("teams"), Equals(Ref("teams.id"), Ref("users.team_id"))),
+		Where(Equals(Ref("teams.name"), Placeholder())),
+		OrderBy("name"), Limit(10), Offset(20),
+	)
+
+	cases := []struct {
+		description string
+		expected    string
+		statement   Statement
+	}{
+		{
+			description: "paginated select",
+			expected:    "select count(*) from (select 1 from users join teams on teams.id = users.team_id where (teams.name = $1)) as _count",
+			statement:   CountRows(page),
+		},
+		{
+			description: "aliased columns",
+			expected:    "select count(*) from (select 1 from users) as _count",
+			statement:   CountRows(Select(Columns(RefAs("id", "user_id"), Ref("u.name")), From(Ref("users")))),
+		},
+		{
+			description: "aggregate select",
+			expected:    "select count(*) from (select sum(total) from orders where (paid)) as _count",
+			statement:   CountRows(Select(Sum(Ref("total")), From(Ref("orders")), Where(Bool("paid")))),
+		},
+		{
+			description: "set returning select",
+			expected:    "select count(*) from (select id, unnest(tags) from posts) as _count",
+			statement:   CountRows(Select(Columns(Ref("id"), Func("unnest", Ref("tags"))), From(Ref("posts")))),
+		},
+		{
+			description: "grouped select",
+			expected:    "select count(*) from (select team_id, count(*) from users group by team_id having (count(*) > $1)) as _count",
+			statement: CountRows(Select(Columns(Ref("team_id"), CountAll()), From(Ref("users")),
+				GroupBy("team_id"), Having(Greater(CountAll(), Placeholder())), OrderBy("team_id"),
+			)),
+		},
+		{
+			description: "distinct select",
+			expected:    "select count(*) from (select distinct team_id from users) as _count",
+			statement:   CountRows(Select(Ref("team_id"), From(Ref("users")), Distinct(), Limit(5))),
+		},
+		{
+			description: "distinct on select",
+			expected:    "select count(*) from (select distinct on (team_id) team_id, name from users) as _count",
+			statement: CountRows(Select(Columns(Ref("team_id"), Ref("name")), From(Ref("users")),
+				DistinctOn(Ref("team_id")), OrderBy("team_id", "name"),
+			)),
+		},
+		{
+			description: "compound",
+			expected:    "select count(*) from ((select id from a where (x = $1)) union (select id from b)) as _count",
+			statement: CountRows(Union(
+				Select(Ref("id"), From(Ref("a")), Where(Equals(Ref("x"), Placeholder()))),
+				Select(Ref("id"), From(Ref("b"))),
+				OrderBy("id"), Limit(10),
+			)),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			is.Equal(c.expected, c.statement.BuildWithDialect(Postgres))
+			is.NoErr(c.statement.Validate())
+		})
+	}
+
+	// the statement being counted is left alone
+	is.Equal("select id, name from users join teams on teams.id = users.team_id where (teams.name = ?) order by name limit 10 offset 20", page.Build())
+}
+
+func TestCountRowsArgs(t *testing.T) {
+	is := is.New(t)
+
+	st := CountRows(Select(Ref("*"), From(Ref("users")), Where(Equals(Ref("team"), Arg(7))), Limit(10)))
+	sql, args := st.BuildArgs()
+
+	is.Equal("select count(*) from (select 1 from users where (team = ?)) as _count", sql)
+	is.Equal([]interface{}{7}, args)
+}
+
+func TestCountRowsErr(t *testing.T) {
+	is := is.New(t)
+
+	st := CountRows(Select(Ref("*"), From(Ref("users")), Where(nil)))
+
+	is.True(errors.Is(st.Err(), ErrNilExpression))
+	is.Equal("", st.Build())
+}
+
+func TestCountRowsInvalid(t *testing.T) {
+	is := is.New(t)
+
+	defer func() {
+		is.True(recover() != nil)
+	}()
+
+	CountRows(Delete(Ref("users")))
+}
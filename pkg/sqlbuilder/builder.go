@@ -1,9 +1,14 @@
 package sqlbuilder
 
 import (
+	"bytes"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
+	"unicode"
 )
 
 const (
@@ -15,8 +20,20 @@ type StatementKind uint
 
 //go:generate stringer -type StatementKind -linecomment
 const (
-	_unknownStatement StatementKind = iota
-	_SelectStatement                // select
+	_unknownStatement      StatementKind = iota
+	_SelectStatement                     // select
+	_UpdateStatement                     // update
+	_InsertStatement                     // insert into
+	_DeleteStatement                     // delete from
+	_UnionStatement                      // union
+	_UnionAllStatement                   // union all
+	_IntersectStatement                  // intersect
+	_ExceptStatement                     // except
+	_IntersectAllStatement               // intersect all
+	_ExceptAllStatement                  // except all
+	_ExplainStatement                    // explain
+	_TruncateStatement                   // truncate table
+	_TableStatement                      // table
 )
 
 type ClauseKind uint
@@ -24,15 +41,68 @@ type ClauseKind uint
 // The order of these is how they must show up in SQL statements. The order is
 // used as the slice index to guarantee the clauses show up in the right spot
 // in the builder.
+//
 //go:generate stringer -type ClauseKind -linecomment
 const (
-	_unknownClause  ClauseKind = iota
-	_FromClause                // from
-	_JoinClause                // join
-	_LeftJoinClause            // left join
-	_WhereClause               // where
-	_GroupByClause             // group by
-	_OrderByClause             // order by
+	_unknownClause         ClauseKind = iota
+	_InsertColumnsClause              // columns
+	_ValuesClause                     // values
+	_DefaultValuesClause              // default values
+	_OnConflictClause                 // on conflict
+	_FromClause                       // from
+	_JoinClause                       // join
+	_LeftJoinClause                   // left join
+	_InnerJoinClause                  // inner join
+	_RightJoinClause                  // right join
+	_FullJoinClause                   // full join
+	_CrossJoinClause                  // cross join
+	_SetClause                        // set
+	_UpdateFromClause                 // from
+	_UsingClause                      // using
+	_WhereClause                      // where
+	_GroupByClause                    // group by
+	_HavingClause                     // having
+	_WindowClause                     // window
+	_OrderByClause                    // order by
+	_LimitClause                      // limit
+	_OffsetClause                     // offset
+	_FetchClause                      // fetch
+	_ReturningClause                  // returning
+	_LockingClause                    // locking
+	_RestartIdentityClause            // restart identity
+	_CascadeClause                    // cascade
+	_CommentClause                    // comment
+)
+
+// The kinds of clauses a statement can have, for inspecting statements with
+// ClausesOfKind.
+const (
+	InsertColumnsClauseKind   = _InsertColumnsClause
+	ValuesClauseKind          = _ValuesClause
+	DefaultValuesClauseKind   = _DefaultValuesClause
+	OnConflictClauseKind      = _OnConflictClause
+	FromClauseKind            = _FromClause
+	JoinClauseKind            = _JoinClause
+	LeftJoinClauseKind        = _LeftJoinClause
+	InnerJoinClauseKind       = _InnerJoinClause
+	RightJoinClauseKind       = _RightJoinClause
+	FullJoinClauseKind        = _FullJoinClause
+	CrossJoinClauseKind       = _CrossJoinClause
+	SetClauseKind             = _SetClause
+	UpdateFromClauseKind      = _UpdateFromClause
+	UsingClauseKind           = _UsingClause
+	WhereClauseKind           = _WhereClause
+	GroupByClauseKind         = _GroupByClause
+	HavingClauseKind          = _HavingClause
+	WindowClauseKind          = _WindowClause
+	OrderByClauseKind         = _OrderByClause
+	LimitClauseKind           = _LimitClause
+	OffsetClauseKind          = _OffsetClause
+	ReturningClauseKind       = _ReturningClause
+	LockingClauseKind         = _LockingClause
+	RestartIdentityClauseKind = _RestartIdentityClause
+	CascadeClauseKind         = _CascadeClause
+	CommentClauseKind         = _CommentClause
 )
 
 type Clause interface {
@@ -41,63 +111,141 @@ type Clause interface {
 	Expression
 }
 
+type insertColumnsClause struct {
+	columns []string
+}
+
+func (c insertColumnsClause) Kind() ClauseKind  { return _InsertColumnsClause }
+func (c insertColumnsClause) Delimeter() string { return ", " }
+
+func (c insertColumnsClause) Build() string {
+	return "(" + strings.Join(c.columns, defaultExpressionDelimeter) + ")"
+}
+
+type valuesClause struct {
+	values []Expression
+}
+
+func (c valuesClause) Kind() ClauseKind  { return _ValuesClause }
+func (c valuesClause) Delimeter() string { return ", " }
+
+func (c valuesClause) Build() string { return c.BuildWith(&BuildContext{}) }
+
+func (c valuesClause) BuildWith(ctx *BuildContext) string {
+	return ctx.Build(Wrap(Columns(c.values...)))
+}
+
+type defaultValuesClause struct{}
+
+func (c defaultValuesClause) Kind() ClauseKind  { return _DefaultValuesClause }
+func (c defaultValuesClause) Delimeter() string { return " " }
+
+func (c defaultValuesClause) Build() string { return c.Kind().String() }
+
+type onConflictClause struct {
+	target    []string
+	update    []Expression
+	doNothing bool
+}
+
+func (c onConflictClause) Kind() ClauseKind  { return _OnConflictClause }
+func (c onConflictClause) Delimeter() string { return " " }
+
+func (c onConflictClause) Build() string { return c.BuildWith(&BuildContext{}) }
+
+func (c onConflictClause) BuildWith(ctx *BuildContext) string {
+	set := setClause{assignments: c.update}
+
+	if !c.doNothing && ctx.Dialect().Supports(FeatureOnDuplicateKeyUpdate) {
+		return "on duplicate key update " + ctx.Build(set)
+	}
+
+	s := c.Kind().String()
+
+	if len(c.target) > 0 {
+		s += " (" + strings.Join(c.target, defaultExpressionDelimeter) + ")"
+	}
+
+	if c.doNothing {
+		return s + " do nothing"
+	}
+
+	return s + " do update set " + ctx.Build(set)
+}
+
 type fromClause struct {
+	kind   ClauseKind
 	tables []Expression
 }
 
-func (c fromClause) Kind() ClauseKind  { return _FromClause }
+func (c fromClause) Kind() ClauseKind  { return c.kind }
 func (c fromClause) Delimeter() string { return ", " }
 
-func (c fromClause) Build() string {
+func (c fromClause) Build() string { return c.BuildWith(&BuildContext{}) }
+
+func (c fromClause) BuildWith(ctx *BuildContext) string {
 	values := make([]string, len(c.tables))
 
 	for i, e := range c.tables {
-		values[i] = e.Build()
+		values[i] = ctx.Build(e)
 	}
 
 	return strings.Join(values, defaultExpressionDelimeter)
 }
 
 type joinClause struct {
+	kind       ClauseKind
 	table      Expression
 	predicates []Expression
+	using      []string
 }
 
-func (c joinClause) Kind() ClauseKind  { return _JoinClause }
+func (c joinClause) Kind() ClauseKind  { return c.kind }
 func (c joinClause) Delimeter() string { return " " }
 
-func (c joinClause) Build() string {
+func (c joinClause) Build() string { return c.BuildWith(&BuildContext{}) }
+
+func (c joinClause) BuildWith(ctx *BuildContext) string {
+	if len(c.using) > 0 {
+		return fmt.Sprintf("%s %s using (%s)",
+			c.Kind().String(),
+			ctx.Build(c.table),
+			strings.Join(c.using, defaultExpressionDelimeter))
+	}
+
+	if len(c.predicates) == 0 {
+		return c.Kind().String() + " " + ctx.Build(c.table)
+	}
+
 	values := make([]string, len(c.predicates))
 
 	for i, e := range c.predicates {
-		values[i] = e.Build()
+		values[i] = ctx.Build(e)
 	}
 
 	return fmt.Sprintf("%s %s on %s",
 		c.Kind().String(),
-		c.table.Build(),
+		ctx.Build(c.table),
 		strings.Join(values, " and "))
 }
 
-type leftJoinClause struct {
-	table      Expression
-	predicates []Expression
+type setClause struct {
+	assignments []Expression
 }
 
-func (c leftJoinClause) Kind() ClauseKind  { return _LeftJoinClause }
-func (c leftJoinClause) Delimeter() string { return " " }
+func (c setClause) Kind() ClauseKind  { return _SetClause }
+func (c setClause) Delimeter() string { return ", " }
 
-func (c leftJoinClause) Build() string {
-	values := make([]string, len(c.predicates))
+func (c setClause) Build() string { return c.BuildWith(&BuildContext{}) }
 
-	for i, e := range c.predicates {
-		values[i] = e.Build()
+func (c setClause) BuildWith(ctx *BuildContext) string {
+	values := make([]string, len(c.assignments))
+
+	for i, e := range c.assignments {
+		values[i] = ctx.Build(e)
 	}
 
-	return fmt.Sprintf("%s %s on %s",
-		c.Kind().String(),
-		c.table.Build(),
-		strings.Join(values, " and "))
+	return strings.Join(values, defaultExpressionDelimeter)
 }
 
 type whereClause struct {
@@ -107,338 +255,2694 @@ type whereClause struct {
 func (c whereClause) Kind() ClauseKind  { return _WhereClause }
 func (c whereClause) Delimeter() string { return " and " }
 
-func (c whereClause) Build() string {
-	return Wrap(c.predicates).Build()
+func (c whereClause) Build() string { return c.BuildWith(&BuildContext{}) }
+
+func (c whereClause) BuildWith(ctx *BuildContext) string {
+	return ctx.Build(Wrap(c.predicates))
 }
 
 type groupByClause struct {
-	columns []string
+	columns  []Expression
+	grouping string
+	sets     [][]string
 }
 
 func (c groupByClause) Kind() ClauseKind  { return _GroupByClause }
 func (c groupByClause) Delimeter() string { return ", " }
 
-func (c groupByClause) Build() string {
-	cols := strings.Join(c.columns, defaultExpressionDelimeter)
-	return c.Kind().String() + " " + cols
+func (c groupByClause) Build() string { return c.BuildWith(&BuildContext{}) }
+
+func (c groupByClause) BuildWith(ctx *BuildContext) string {
+	cols := ctx.Build(MultiExpression{
+		Delimeter:   defaultExpressionDelimeter,
+		Expressions: c.columns,
+	})
+
+	switch c.grouping {
+	case "":
+		return cols
+	case "grouping sets":
+		sets := make([]string, len(c.sets))
+
+		for i, set := range c.sets {
+			sets[i] = "(" + strings.Join(set, defaultExpressionDelimeter) + ")"
+		}
+
+		cols = strings.Join(sets, defaultExpressionDelimeter)
+	}
+
+	return c.grouping + " (" + cols + ")"
+}
+
+type havingClause struct {
+	predicates MultiExpression
+}
+
+func (c havingClause) Kind() ClauseKind  { return _HavingClause }
+func (c havingClause) Delimeter() string { return " and " }
+
+func (c havingClause) Build() string { return c.BuildWith(&BuildContext{}) }
+
+func (c havingClause) BuildWith(ctx *BuildContext) string {
+	return ctx.Build(Wrap(c.predicates))
+}
+
+type windowClause struct {
+	name string
+	spec MultiExpression
+}
+
+func (c windowClause) Kind() ClauseKind  { return _WindowClause }
+func (c windowClause) Delimeter() string { return ", " }
+
+func (c windowClause) Build() string { return c.BuildWith(&BuildContext{}) }
+
+func (c windowClause) BuildWith(ctx *BuildContext) string {
+	return c.name + " as " + ctx.Build(Wrap(c.spec))
 }
 
 type orderByClause struct {
-	columns []string
+	columns []Expression
 }
 
 func (c orderByClause) Kind() ClauseKind  { return _OrderByClause }
 func (c orderByClause) Delimeter() string { return ", " }
 
-func (c orderByClause) Build() string {
-	cols := strings.Join(c.columns, defaultExpressionDelimeter)
-	return c.Kind().String() + " " + cols
-}
+func (c orderByClause) Build() string { return c.BuildWith(&BuildContext{}) }
 
-// TODO remove this. Must become an expression or statement. Currently exists
-// to hack in window functions.
-func OrderByC(cols ...string) Clause {
-	return orderByClause{columns: cols}
+func (c orderByClause) BuildWith(ctx *BuildContext) string {
+	cols := MultiExpression{
+		Delimeter:   defaultExpressionDelimeter,
+		Expressions: c.columns,
+	}
+
+	return c.Kind().String() + " " + ctx.Build(cols)
 }
 
-type Expression interface {
-	Build() string
+type limitClause struct {
+	count Expression
 }
 
-type ExpressionFunc func() string
+func (c limitClause) Kind() ClauseKind  { return _LimitClause }
+func (c limitClause) Delimeter() string { return " " }
 
-func (e ExpressionFunc) Build() string {
-	return e()
-}
+func (c limitClause) Build() string { return c.BuildWith(&BuildContext{}) }
 
-func Ref(name string) ExpressionFunc {
-	return func() string {
-		return name
+func (c limitClause) BuildWith(ctx *BuildContext) string {
+	if ctx.Dialect().Supports(FeatureOffsetFetch) {
+		return "fetch next " + ctx.Build(c.count) + " rows only"
 	}
+
+	return c.Kind().String() + " " + ctx.Build(c.count)
 }
 
-func Const(value string) ExpressionFunc {
-	return func() string {
-		return "'" + value + "'"
-	}
+type offsetClause struct {
+	start Expression
 }
 
-func As(expr Expression, alias string) ExpressionFunc {
-	return func() string {
-		return expr.Build() + " as " + Const(alias).Build()
+func (c offsetClause) Kind() ClauseKind  { return _OffsetClause }
+func (c offsetClause) Delimeter() string { return " " }
+
+func (c offsetClause) Build() string { return c.BuildWith(&BuildContext{}) }
+
+func (c offsetClause) BuildWith(ctx *BuildContext) string {
+	if ctx.Dialect().Supports(FeatureOffsetFetch) {
+		return c.Kind().String() + " " + ctx.Build(c.start) + " rows"
 	}
+
+	return c.Kind().String() + " " + ctx.Build(c.start)
 }
 
-func RefAs(name, alias string) ExpressionFunc {
-	return As(Ref(name), alias)
+type returningClause struct {
+	columns []Expression
 }
 
-func Window(fn string, clause Clause) ExpressionFunc {
-	return func() string {
-		return fmt.Sprintf("%s over (%s)", fn, clause.Build())
-	}
+func (c returningClause) Kind() ClauseKind  { return _ReturningClause }
+func (c returningClause) Delimeter() string { return ", " }
+
+func (c returningClause) Build() string { return c.BuildWith(&BuildContext{}) }
+
+func (c returningClause) BuildWith(ctx *BuildContext) string {
+	return ctx.Build(Columns(c.columns...))
 }
 
-func Func(fn string, args ...Expression) ExpressionFunc {
-	call := Ref(fn)
-	me := MultiExpression{
-		Delimeter:   defaultExpressionDelimeter,
-		Expressions: args,
+type lockingClause struct {
+	strength string
+	tables   []string
+	wait     string
+}
+
+func (c lockingClause) Kind() ClauseKind  { return _LockingClause }
+func (c lockingClause) Delimeter() string { return " " }
+
+func (c lockingClause) Build() string { return c.BuildWith(&BuildContext{}) }
+
+func (c lockingClause) BuildWith(ctx *BuildContext) string {
+	strength := c.strength
+	if strength == "" {
+		strength = "update"
 	}
 
-	return func() string {
-		return call.Build() + Wrap(me).Build()
+	s := "for " + strength
+
+	if len(c.tables) > 0 {
+		s += " of " + strings.Join(c.tables, defaultExpressionDelimeter)
 	}
-}
 
-func Wrap(expr Expression) ExpressionFunc {
-	return func() string {
-		return "(" + expr.Build() + ")"
+	if c.wait != "" {
+		s += " " + c.wait
 	}
+
+	return s
 }
 
-func Columns(cols ...Expression) Expression {
-	return MultiExpression{
-		Delimeter:   defaultExpressionDelimeter,
-		Expressions: cols,
-	}
+// keywordClause is a clause made of only its keyword.
+type keywordClause struct {
+	kind ClauseKind
 }
 
-func Predicate(op string, left, right Expression) ExpressionFunc {
-	return func() string {
-		s := left.Build() + " " + op
+func (c keywordClause) Kind() ClauseKind  { return c.kind }
+func (c keywordClause) Delimeter() string { return " " }
 
-		if right != nil {
-			s += " " + right.Build()
-		}
+func (c keywordClause) Build() string { return c.kind.String() }
 
-		return s
+type commentClause struct {
+	tags map[string]string
+}
+
+func (c commentClause) Kind() ClauseKind  { return _CommentClause }
+func (c commentClause) Delimeter() string { return " " }
+
+func (c commentClause) Build() string {
+	keys := make([]string, 0, len(c.tags))
+	for k := range c.tags {
+		keys = append(keys, k)
 	}
+
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = sanitizeComment(k) + "='" + strings.ReplaceAll(sanitizeComment(c.tags[k]), "'", `\'`) + "'"
+	}
+
+	return "/* " + strings.Join(pairs, ",") + " */"
 }
 
-func Equals(left, right Expression) ExpressionFunc {
-	return Predicate("=", left, right)
+// sanitizeComment breaks up anything in s that would start or end a comment.
+func sanitizeComment(s string) string {
+	s = strings.ReplaceAll(s, "*/", "* /")
+	return strings.ReplaceAll(s, "/*", "/ *")
 }
 
-func Greater(left, right Expression) ExpressionFunc {
-	return Predicate(">", left, right)
+// OrderByC builds an order by for use outside of a statement, such as in a
+// Window spec.
+func OrderByC(cols ...string) Clause {
+	return orderByClause{columns: refs(cols)}
 }
 
-func Less(left, right Expression) ExpressionFunc {
-	return Predicate("<", left, right)
+type Expression interface {
+	Build() string
 }
 
-func GreaterOrEqual(left, right Expression) ExpressionFunc {
-	return Predicate(">=", left, right)
+// ContextExpression is an Expression that can be built as part of a larger
+// tree, sharing a BuildContext with the rest of the tree. Expressions that
+// contain other expressions should implement it and build their children with
+// BuildContext.Build so arguments are collected in the order they appear.
+type ContextExpression interface {
+	Expression
+	BuildWith(ctx *BuildContext) string
 }
 
-func LessOrEqual(left, right Expression) ExpressionFunc {
-	return Predicate("<=", left, right)
+// BuildContext carries the state that is shared by every expression in a tree
+// while it's being built, such as the dialect and the arguments bound with Arg.
+type BuildContext struct {
+	dialect Dialect
+	args    []interface{}
+	params  []param
+	named   map[string]interface{}
+
+	// debug writes bound values inline in place of their placeholders, see
+	// RenderDebug.
+	debug bool
+
+	// placeholderFormat overrides the placeholders of the dialect, see
+	// WithPlaceholder.
+	placeholderFormat string
 }
 
-func In(left, right Expression) ExpressionFunc {
-	return Predicate("in", left, Wrap(right))
+// param is a placeholder in a built statement along with the value bound to it
+// when it was built with Arg.
+type param struct {
+	value interface{}
+	bound bool
 }
 
-func Like(left, right Expression) ExpressionFunc {
-	return Predicate("like", left, right)
+// NewBuildContext returns a BuildContext that builds expressions for d. A nil
+// Dialect uses the default dialect set with SetDefaultDialect.
+func NewBuildContext(d Dialect) *BuildContext {
+	return &BuildContext{dialect: d}
 }
 
-func NotLike(left, right Expression) ExpressionFunc {
-	return Predicate("not like", left, right)
+// Dialect returns the Dialect expressions are being built for. Without one
+// the default dialect is used for the rest of the build.
+func (ctx *BuildContext) Dialect() Dialect {
+	if ctx.dialect == nil {
+		ctx.dialect = DefaultDialect()
+	}
+
+	return ctx.dialect
 }
 
-func Between(left, right Expression) ExpressionFunc {
-	return Predicate("between", left, right)
+// Placeholder returns the next placeholder for the dialect being built.
+func (ctx *BuildContext) Placeholder() string {
+	return ctx.placeholder(param{})
 }
 
-func IsNull(expr Expression) ExpressionFunc {
-	return Predicate("is null", expr, nil)
+// BindArg returns the next placeholder for the dialect being built and binds
+// value to it, so it's included in Args.
+func (ctx *BuildContext) BindArg(value interface{}) string {
+	ctx.args = append(ctx.args, value)
+
+	return ctx.placeholder(param{value: value, bound: true})
 }
 
-func IsNotNull(expr Expression) ExpressionFunc {
-	return Predicate("is not null", expr, nil)
+func (ctx *BuildContext) placeholder(p param) string {
+	ctx.params = append(ctx.params, p)
+
+	if ctx.debug && p.bound {
+		return renderValue(ctx.Dialect(), p.value)
+	}
+
+	if ctx.placeholderFormat != "" {
+		return strings.ReplaceAll(ctx.placeholderFormat, "%d", strconv.Itoa(len(ctx.params)))
+	}
+
+	return ctx.Dialect().Placeholder(len(ctx.params))
 }
 
-func Placeholder() ExpressionFunc {
-	return func() string {
-		return defaultPlaceholder
+// numberedPlaceholders reports whether placeholders are numbered, like "$1",
+// so they can be built out of order, rather than positional like "?".
+func (ctx *BuildContext) numberedPlaceholders() bool {
+	if ctx.placeholderFormat != "" {
+		return strings.Contains(ctx.placeholderFormat, "%d")
 	}
+
+	return ctx.Dialect().Placeholder(1) != ctx.Dialect().Placeholder(2)
 }
 
-type MultiExpression struct {
-	Delimeter   string
-	Expressions []Expression
+// Build builds expr using ctx when expr is a ContextExpression, falling back to
+// expr.Build otherwise.
+func (ctx *BuildContext) Build(expr Expression) string {
+	if ce, ok := expr.(ContextExpression); ok {
+		return ce.BuildWith(ctx)
+	}
+
+	return expr.Build()
 }
 
-func (e MultiExpression) Build() string {
-	sl := SimpleListExpression{Delimeter: e.Delimeter}
+// Args returns the arguments bound so far, in the order their placeholders
+// were built.
+func (ctx *BuildContext) Args() []interface{} {
+	return ctx.args
+}
 
-	for _, expr := range e.Expressions {
-		sl.Values = append(sl.Values, expr.Build())
-	}
+type ExpressionFunc func() string
 
-	return sl.Build()
+func (e ExpressionFunc) Build() string {
+	return e()
 }
 
-type SimpleListExpression struct {
-	Delimeter string
-	Values    []string
+// ContextExpressionFunc is an Expression built by calling the function with the
+// BuildContext of the tree it's being built in. Use it over ExpressionFunc for
+// expressions that bind args or depend on the dialect.
+type ContextExpressionFunc func(ctx *BuildContext) string
+
+func (e ContextExpressionFunc) Build() string {
+	return e(&BuildContext{})
 }
 
-func (e SimpleListExpression) Build() string {
-	return strings.Join(e.Values, e.Delimeter)
+func (e ContextExpressionFunc) BuildWith(ctx *BuildContext) string {
+	return e(ctx)
 }
 
-type StatementOption func(*Statement)
+func Ref(name string) Expression {
+	return RefExpr{Name: name}
+}
 
-type Statement struct {
-	Kind        StatementKind
-	Expressions []Expression
-	Clauses     []Clause
+// Quote builds name as a quoted identifier for the dialect being built,
+// quoting each dot separated part on its own so Quote("public.order") builds
+// "public"."order". Use it for names that are keywords or otherwise need
+// quoting; Ref leaves names as they are.
+func Quote(name string) ContextExpressionFunc {
+	return QuoteParts(strings.Split(name, ".")...)
 }
 
-func (s Statement) Build() string {
-	builder := strings.Builder{}
-	clauses := make([]*clauseBuilder, len(_ClauseKind_index))
-	onceClauses := make(map[ClauseKind]*sync.Once)
+// QuoteParts is like Quote but takes the parts of the name separately, so a
+// part can itself contain a dot: QuoteParts("public", "my.table") builds
+// "public"."my.table".
+func QuoteParts(parts ...string) ContextExpressionFunc {
+	return func(ctx *BuildContext) string {
+		quoted := make([]string, len(parts))
 
-	switch s.Kind {
-	case _SelectStatement:
-		onceClauses = map[ClauseKind]*sync.Once{
-			_WhereClause: &sync.Once{},
-			_FromClause:  &sync.Once{},
+		for i, part := range parts {
+			quoted[i] = ctx.Dialect().QuoteIdentifier(part)
 		}
+
+		return strings.Join(quoted, ".")
 	}
+}
+
+// Table builds a schema qualified table name quoted for the dialect being
+// built, such as "public"."users". Without a schema it builds just "users".
+func Table(schema, name string) ContextExpressionFunc {
+	return qualified(schema, name)
+}
 
-	builder.WriteString(s.Kind.String() + " ")
+// Column builds a table qualified column name quoted for the dialect being
+// built, such as "u"."id". Without a table it builds just "id".
+func Column(table, name string) ContextExpressionFunc {
+	return qualified(table, name)
+}
 
-	for _, expr := range s.Expressions {
-		builder.WriteString(expr.Build() + " ")
+func qualified(qualifier, name string) ContextExpressionFunc {
+	if qualifier == "" {
+		return QuoteParts(name)
 	}
 
-	for _, clause := range s.Clauses {
-		kind := clause.Kind()
+	return QuoteParts(qualifier, name)
+}
+
+// Const builds value as a string literal, escaped for the dialect being
+// built.
+func Const(value string) ContextExpressionFunc {
+	return func(ctx *BuildContext) string {
+		return ctx.Dialect().QuoteString(value)
+	}
+}
+
+// As aliases expr. The alias is used as-is when it's a plain lowercase
+// identifier and quoted for the dialect being built otherwise, including when
+// it has uppercase letters or is a reserved word, like "user".
+func As(expr Expression, alias string) Expression {
+	return AliasExpr{Expr: expr, Alias: alias}
+}
+
+func RefAs(name, alias string) Expression {
+	return As(Ref(name), alias)
+}
 
-		if clauses[kind] == nil {
-			clauses[kind] = &clauseBuilder{
-				kind: kind,
-				me:   &MultiExpression{Delimeter: clause.Delimeter()},
+// Window calls fn over a window built from spec, such as PartitionBy and
+// OrderByC, building "fn over (partition by a order by b)". Without a spec it
+// builds "fn over ()". A spec of just UseWindow builds "fn over w".
+func Window(fn string, spec ...Expression) Expression {
+	return nodeExpr{exprs: spec, build: func(ctx *BuildContext) string {
+		if len(spec) == 1 {
+			if w, ok := spec[0].(windowName); ok {
+				return fn + " over " + w.Build()
 			}
 		}
 
-		cb := clauses[kind]
-		cb.me.Expressions = append(cb.me.Expressions, clause)
-		clauses[kind] = cb
+		me := MultiExpression{
+			Delimeter:   " ",
+			Expressions: spec,
+		}
+
+		return fmt.Sprintf("%s over (%s)", fn, ctx.Build(me))
+	}}
+}
+
+type windowName string
+
+func (w windowName) Build() string { return string(w) }
+
+// UseWindow refers to a window added to the statement with NamedWindow. On its
+// own in Window it builds "fn over name". With other parts of a spec it's
+// built as the window the rest of the spec extends, "fn over (name order by
+// b)".
+func UseWindow(name string) Expression {
+	return windowName(name)
+}
+
+// PartitionBy builds the "partition by a, b" part of a window spec.
+func PartitionBy(cols ...string) Expression {
+	me := MultiExpression{
+		Delimeter:   defaultExpressionDelimeter,
+		Expressions: refs(cols),
 	}
 
-	for _, group := range clauses {
-		if group != nil {
-			kind := group.kind
+	return nodeExpr{exprs: me.Expressions, build: func(ctx *BuildContext) string {
+		return "partition by " + ctx.Build(me)
+	}}
+}
 
-			if _, ok := onceClauses[kind]; ok {
-				once := onceClauses[kind]
-				once.Do(func() {
-					builder.WriteString(kind.String() + " ")
-				})
-			}
+// Frame builds the frame part of a window spec, "mode between start and end",
+// such as Frame("rows", "unbounded preceding", "current row") for a running
+// total. Without an end it builds "mode start". Frame panics when mode isn't
+// "rows", "range" or "groups".
+func Frame(mode, start, end string) ContextExpressionFunc {
+	switch mode {
+	case "rows", "range", "groups":
+	default:
+		panic(fmt.Sprintf("sqlbuilder: invalid frame mode %q", mode))
+	}
 
-			builder.WriteString(group.me.Build() + " ")
+	return func(ctx *BuildContext) string {
+		if end == "" {
+			return mode + " " + start
 		}
+
+		return mode + " between " + start + " and " + end
 	}
+}
 
-	return strings.TrimSpace(builder.String())
+func Func(fn string, args ...Expression) Expression {
+	return FuncExpr{Name: fn, Args: args}
 }
 
-type clauseBuilder struct {
-	kind ClauseKind
-	me   *MultiExpression
+func Wrap(expr Expression) Expression {
+	return WrapExpr{Expr: expr}
 }
 
-// Select takes an expression as the column or columns and 0 or more options
-// that modify the statement object to build the query.
-func Select(columns Expression, opts ...StatementOption) Statement {
-	st := Statement{
-		Kind:        _SelectStatement,
-		Expressions: []Expression{columns},
-	}
+// Infix builds "(left op right)". The parentheses keep the precedence of the
+// operation when it's nested in other expressions.
+func Infix(op string, left, right Expression) Expression {
+	return Wrap(Predicate(op, left, right))
+}
 
-	for _, opt := range opts {
-		opt(&st)
-	}
+func Add(left, right Expression) Expression {
+	return Infix("+", left, right)
+}
 
-	return st
+func Sub(left, right Expression) Expression {
+	return Infix("-", left, right)
 }
 
-// From takes a list of expressions to use as a TableExpression list for the
-// sql-from clause. The list is joined in argument order on ", ".
-func From(tables ...Expression) StatementOption {
-	return func(st *Statement) {
-		st.Clauses = append(st.Clauses, fromClause{tables: tables})
-	}
+func Mul(left, right Expression) Expression {
+	return Infix("*", left, right)
 }
 
-// FromSubselect takes a Statement and an optional as and returns it, wrapped in
-// (), to the sql-from clause.
-func FromSubselect(sub Statement, as string) StatementOption {
-	expr := Wrap(sub)
-	if as != "" {
-		expr = As(expr, as)
-	}
+func Div(left, right Expression) Expression {
+	return Infix("/", left, right)
+}
 
-	return From(expr)
+func Mod(left, right Expression) Expression {
+	return Infix("%", left, right)
 }
 
-func Join(table Expression, predicates ...Expression) StatementOption {
-	return func(st *Statement) {
-		st.Clauses = append(st.Clauses, joinClause{
-			table:      table,
-			predicates: predicates,
-		})
-	}
+func BitAnd(left, right Expression) Expression {
+	return Infix("&", left, right)
 }
 
-func LeftJoin(table Expression, predicates ...Expression) StatementOption {
-	return func(st *Statement) {
-		st.Clauses = append(st.Clauses, leftJoinClause{
-			table:      table,
-			predicates: predicates,
-		})
-	}
+func BitOr(left, right Expression) Expression {
+	return Infix("|", left, right)
 }
 
-// Where takes a list of expressions that are expected to be predicates of some
-// kind. These are then join with " and " and wrapped in "()". Multiple uses of
-// this StatementOption will only result in a single "where" clause with each
-// distinct group of predicates wrapped in their own "()" and join with " and ".
-func Where(predicates ...Expression) StatementOption {
-	return func(st *Statement) {
-		me := MultiExpression{
-			Delimeter:   " and ",
-			Expressions: predicates,
+// BitXor builds "(left ^ right)", or "(left # right)" for dialects like
+// Postgres where "^" is exponentiation.
+func BitXor(left, right Expression) Expression {
+	return nodeExpr{exprs: []Expression{left, right}, build: func(ctx *BuildContext) string {
+		if ctx.Dialect().Supports(FeatureHashBitXor) {
+			return ctx.Build(Infix("#", left, right))
 		}
 
-		st.Clauses = append(st.Clauses, whereClause{predicates: me})
-	}
+		return ctx.Build(Infix("^", left, right))
+	}}
 }
 
-// OrderBy takes a list of expressions and adds an order by clause to the
-// statement.
-//
-// TODO make cols an expression (easier to add an expression func like RefDesc
-// for sort direction.
-func OrderBy(cols ...string) StatementOption {
-	return func(st *Statement) {
-		st.Clauses = append(st.Clauses, orderByClause{columns: cols})
-	}
+func ShiftLeft(left, right Expression) Expression {
+	return Infix("<<", left, right)
 }
 
-// GroupBy takes a list of expressions and adds an order by clause to the
-// statement.
-//
-// TODO make cols an expression
-func GroupBy(cols ...string) StatementOption {
+func ShiftRight(left, right Expression) Expression {
+	return Infix(">>", left, right)
+}
+
+// Neg negates expr, building "(-expr)". Like Infix it's wrapped in "()" so it
+// keeps its precedence, and so negating a negation never builds "--", which
+// starts a comment.
+func Neg(expr Expression) Expression {
+	return Prefix("-", expr)
+}
+
+// BitNot inverts the bits of expr, building "(~expr)".
+func BitNot(expr Expression) Expression {
+	return Prefix("~", expr)
+}
+
+// Prefix builds the unary operator op applied to expr, wrapped in "()".
+func Prefix(op string, expr Expression) Expression {
+	return nodeExpr{exprs: []Expression{expr}, build: func(ctx *BuildContext) string {
+		return "(" + op + ctx.Build(expr) + ")"
+	}}
+}
+
+// Concat concatenates exprs as strings. It builds "a || b" by default and
+// "concat(a, b)" for dialects, like MySQL, that don't have the || operator.
+func Concat(exprs ...Expression) Expression {
+	return nodeExpr{exprs: exprs, build: func(ctx *BuildContext) string {
+		values := make([]string, len(exprs))
+
+		for i, expr := range exprs {
+			values[i] = ctx.Build(expr)
+		}
+
+		return ctx.Dialect().Concat(values)
+	}}
+}
+
+// Cast converts expr to typ, building "cast(expr as typ)".
+func Cast(expr Expression, typ string) Expression {
+	return CastExpr{Expr: expr, Type: typ}
+}
+
+// CastOp is the postgres shorthand for Cast, building "expr::typ". Operands
+// like "data ->> 'age'" are wrapped so the cast applies to all of them,
+// building "(data ->> 'age')::int", while single terms such as columns,
+// function calls and other casts aren't, so casts chain as "x::text::int".
+func CastOp(expr Expression, typ string) Expression {
+	return CastExpr{Expr: expr, Type: typ, Shorthand: true}
+}
+
+// isTerm reports whether the built expression s is a single term, meaning it
+// has no whitespace outside of parentheses and quotes.
+func isTerm(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	depth := 0
+	var quote rune
+
+	for _, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'', r == '"', r == '`':
+			quote = r
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		case depth == 0 && unicode.IsSpace(r):
+			return false
+		}
+	}
+
+	return true
+}
+
+// refs returns a Ref for each name in names.
+func refs(names []string) []Expression {
+	exprs := make([]Expression, len(names))
+
+	for i, name := range names {
+		exprs[i] = Ref(name)
+	}
+
+	return exprs
+}
+
+func Columns(cols ...Expression) Expression {
+	return MultiExpression{
+		Delimeter:   defaultExpressionDelimeter,
+		Expressions: cols,
+	}
+}
+
+func Predicate(op string, left, right Expression) Expression {
+	return PredicateExpr{Op: op, Left: left, Right: right}
+}
+
+func Equals(left, right Expression) Expression {
+	return Predicate("=", left, right)
+}
+
+func NotEquals(left, right Expression) Expression {
+	return Predicate("<>", left, right)
+}
+
+// EqualsInt compares col to v, building "col = ?" with v bound to the
+// placeholder.
+func EqualsInt(col string, v int) Expression {
+	return Equals(Ref(col), Arg(v))
+}
+
+// EqualsString compares col to v, building "col = ?" with v bound to the
+// placeholder.
+func EqualsString(col string, v string) Expression {
+	return Equals(Ref(col), Arg(v))
+}
+
+// EqualsTime compares col to t, building "col = ?" with t bound to the
+// placeholder.
+func EqualsTime(col string, t time.Time) Expression {
+	return Equals(Ref(col), Arg(t))
+}
+
+func Greater(left, right Expression) Expression {
+	return Predicate(">", left, right)
+}
+
+func Less(left, right Expression) Expression {
+	return Predicate("<", left, right)
+}
+
+func GreaterOrEqual(left, right Expression) Expression {
+	return Predicate(">=", left, right)
+}
+
+func LessOrEqual(left, right Expression) Expression {
+	return Predicate("<=", left, right)
+}
+
+func In(left, right Expression) Expression {
+	return Predicate("in", left, Wrap(right))
+}
+
+// InValues checks left against a list of values, building
+// "left in (a, b, c)". An empty list builds "left in (null)", which matches
+// nothing, instead of the invalid "in ()".
+func InValues(left Expression, vals ...Expression) Expression {
+	if len(vals) == 0 {
+		return In(left, Ref("null"))
+	}
+
+	return In(left, Columns(vals...))
+}
+
+// InList is like InValues with n placeholders as the values, building
+// "left in (?, ?, ...)".
+func InList(left Expression, n int) Expression {
+	vals := make([]Expression, n)
+
+	for i := range vals {
+		vals[i] = Placeholder()
+	}
+
+	return InValues(left, vals...)
+}
+
+// RowInValues checks a row of cols against a list of rows, building
+// "(a, b) in ((x, y), (z, w))". RowInValues panics when a row doesn't have one
+// value per column. An empty list of rows builds a single row of nulls, which
+// matches nothing.
+func RowInValues(cols []Expression, rows ...[]Expression) Expression {
+	if len(rows) == 0 {
+		nulls := make([]Expression, len(cols))
+
+		for i := range nulls {
+			nulls[i] = Ref("null")
+		}
+
+		rows = [][]Expression{nulls}
+	}
+
+	tuples := make([]Expression, len(rows))
+
+	for i, row := range rows {
+		if len(row) != len(cols) {
+			panic(fmt.Sprintf("sqlbuilder: row %d has %d values for %d columns", i, len(row), len(cols)))
+		}
+
+		tuples[i] = Wrap(Columns(row...))
+	}
+
+	return In(Wrap(Columns(cols...)), Columns(tuples...))
+}
+
+// RowIn is like RowInValues with n rows of placeholders, building
+// "(a, b) in ((?, ?), (?, ?))".
+func RowIn(cols []Expression, n int) Expression {
+	rows := make([][]Expression, n)
+
+	for i := range rows {
+		rows[i] = make([]Expression, len(cols))
+
+		for j := range rows[i] {
+			rows[i][j] = Placeholder()
+		}
+	}
+
+	return RowInValues(cols, rows...)
+}
+
+// NotIn checks left isn't in right, building "left not in (right)". Beware
+// that it's never true when right contains a null, so use NotInSafe for
+// subqueries that can return nulls.
+func NotIn(left, right Expression) Expression {
+	return Predicate("not in", left, Wrap(right))
+}
+
+// InSubquery checks left against the rows returned by sub, building
+// "left in (sub)". Unlike In, sub is always wrapped exactly once, so there's no
+// way to end up with "in ((select ...))".
+func InSubquery(left Expression, sub Statement) Expression {
+	return Predicate("in", left, Wrap(sub))
+}
+
+// NotInSubquery is the negated form of InSubquery, building
+// "left not in (sub)". Like NotIn it's never true when sub returns a null, in
+// which case no rows match at all; use NotInSafe instead.
+func NotInSubquery(left Expression, sub Statement) Expression {
+	return Predicate("not in", left, Wrap(sub))
+}
+
+// NotInSafe is a null safe NotInSubquery. It's true when no row returned by
+// sub equals left, even if sub returns nulls, building
+// "not exists (select 1 from (select col as value ...) as _not_in where (_not_in.value = left))".
+// Comparing through the derived table keeps left from being resolved against
+// the tables of sub. An alias sub already gives its column is replaced. A sub
+// that isn't a select, such as a compound, names its column with
+// "as _not_in(value)" instead, which SQLite doesn't support.
+//
+// sub must select a single column. When it selects "*" or more than one
+// column the statement NotInSafe is used in fails to validate with an error
+// wrapping ErrInvalidClause.
+//
+// Unlike "not in" it's also true when left is null.
+func NotInSafe(left Expression, sub Statement) Expression {
+	match := Where(Equals(Ref("_not_in.value"), left))
+
+	col, err := singleColumn(sub)
+	if err != nil {
+		st := Select(Ref("1"), FromSubselect(sub, "_not_in"), match)
+		st.err = err
+
+		return NotExists(st)
+	}
+
+	if sub.Kind == _SelectStatement {
+		aliased := sub.Clone()
+		aliased.Expressions = []Expression{As(col, "value")}
+
+		return NotExists(Select(Ref("1"), FromSubselect(aliased, "_not_in"), match))
+	}
+
+	return NotExists(Select(Ref("1"), FromSubselect(sub, "_not_in", "value"), match))
+}
+
+// singleColumn returns the column selected by sub, without any alias it's
+// given, or an error when sub doesn't select exactly one column. The column
+// of a compound is taken from its first operand.
+func singleColumn(sub Statement) (Expression, error) {
+	if sub.isCompound() && len(sub.Expressions) > 0 {
+		if first, ok := sub.Expressions[0].(Statement); ok {
+			return singleColumn(first)
+		}
+	}
+
+	if sub.Kind != _SelectStatement || len(sub.Expressions) != 1 {
+		return nil, fmt.Errorf("%w: %s statement can't be used as a single column subquery", ErrInvalidClause, sub.Kind)
+	}
+
+	col := sub.Expressions[0]
+
+	if me, ok := col.(MultiExpression); ok {
+		if len(me.Expressions) != 1 {
+			return nil, fmt.Errorf("%w: subquery selects %d columns, not one", ErrInvalidClause, len(me.Expressions))
+		}
+
+		col = me.Expressions[0]
+	}
+
+	if alias, ok := col.(AliasExpr); ok {
+		col = alias.Expr
+	}
+
+	if ref, ok := col.(RefExpr); ok && (ref.Name == "*" || strings.HasSuffix(ref.Name, ".*")) {
+		return nil, fmt.Errorf("%w: subquery selects %s, not a single column", ErrInvalidClause, ref.Name)
+	}
+
+	return col, nil
+}
+
+func Like(left, right Expression) Expression {
+	return Predicate("like", left, right)
+}
+
+func NotLike(left, right Expression) Expression {
+	return Predicate("not like", left, right)
+}
+
+// LikeEscape is like Like but with escape as the character that escapes "%"
+// and "_" in the pattern, building "left like right escape '\'".
+func LikeEscape(left, right Expression, escape string) Expression {
+	return Predicate("escape", Like(left, right), Const(escape))
+}
+
+// ILike is the Postgres case insensitive Like, building "left ilike right".
+func ILike(left, right Expression) Expression {
+	return Predicate("ilike", left, right)
+}
+
+// NotILike is the Postgres case insensitive NotLike, building
+// "left not ilike right".
+func NotILike(left, right Expression) Expression {
+	return Predicate("not ilike", left, right)
+}
+
+// Matches is true when left matches the regular expression right, building
+// "left ~ right". With MySQL it builds "regexp_like(left, right, 'c')".
+func Matches(left, right Expression) Expression {
+	return regexMatch("~", "c", left, right)
+}
+
+// MatchesCI is like Matches but ignores case, building "left ~* right". With
+// MySQL it builds "regexp_like(left, right, 'i')".
+func MatchesCI(left, right Expression) Expression {
+	return regexMatch("~*", "i", left, right)
+}
+
+// NotMatches is the negation of Matches, building "left !~ right". With MySQL
+// it builds "not regexp_like(left, right, 'c')".
+func NotMatches(left, right Expression) Expression {
+	return regexMatch("!~", "c", left, right)
+}
+
+// NotMatchesCI is the negation of MatchesCI, building "left !~* right". With
+// MySQL it builds "not regexp_like(left, right, 'i')".
+func NotMatchesCI(left, right Expression) Expression {
+	return regexMatch("!~*", "i", left, right)
+}
+
+// regexMatch builds the regex operator op, falling back to regexp_like with
+// flags for dialects that support it instead.
+func regexMatch(op, flags string, left, right Expression) Expression {
+	return nodeExpr{exprs: []Expression{left, right}, build: func(ctx *BuildContext) string {
+		if !ctx.Dialect().Supports(FeatureRegexpLike) {
+			return ctx.Build(Predicate(op, left, right))
+		}
+
+		s := ctx.Build(Func("regexp_like", left, right, Const(flags)))
+		if strings.HasPrefix(op, "!") {
+			s = "not " + s
+		}
+
+		return s
+	}}
+}
+
+// CaseInsensitiveLike matches left against the pattern right ignoring case. It
+// builds "left ilike right" for dialects that support it and
+// "lower(left) like lower(right)" otherwise.
+func CaseInsensitiveLike(left, right Expression) Expression {
+	return nodeExpr{exprs: []Expression{left, right}, build: func(ctx *BuildContext) string {
+		if ctx.Dialect().Supports(FeatureILike) {
+			return ctx.Build(ILike(left, right))
+		}
+
+		return ctx.Build(Like(Func("lower", left), Func("lower", right)))
+	}}
+}
+
+func Between(left, right Expression) Expression {
+	return Predicate("between", left, right)
+}
+
+func NotBetween(left, right Expression) Expression {
+	return Predicate("not between", left, right)
+}
+
+// BetweenAnd checks col against an inclusive range, building
+// "col between low and high".
+func BetweenAnd(col, low, high Expression) Expression {
+	return Predicate("between", col, Predicate("and", low, high))
+}
+
+// NotBetweenAnd is the negation of BetweenAnd, building
+// "col not between low and high".
+func NotBetweenAnd(col, low, high Expression) Expression {
+	return Predicate("not between", col, Predicate("and", low, high))
+}
+
+// Bool uses the boolean column col as a predicate on its own, building
+// "where (active)" from Where(Bool("active")). Negate it with Not.
+func Bool(col string) Expression {
+	return Ref(col)
+}
+
+func IsNull(expr Expression) Expression {
+	return Predicate("is null", expr, nil)
+}
+
+func IsNotNull(expr Expression) Expression {
+	return Predicate("is not null", expr, nil)
+}
+
+// IsDistinctFrom is a null safe not equals, building
+// "left is distinct from right". Unlike NotEquals it's true when only one side
+// is null and false when both are.
+func IsDistinctFrom(left, right Expression) Expression {
+	return Predicate("is distinct from", left, right)
+}
+
+// IsNotDistinctFrom is a null safe equals, building
+// "left is not distinct from right". Unlike Equals it's true when both sides
+// are null.
+func IsNotDistinctFrom(left, right Expression) Expression {
+	return Predicate("is not distinct from", left, right)
+}
+
+// Exists is true when sub returns any rows, building "exists (sub)".
+func Exists(sub Statement) Expression {
+	return UnaryExpr{Op: "exists", Expr: Wrap(sub)}
+}
+
+// NotExists is true when sub returns no rows, building "not exists (sub)".
+func NotExists(sub Statement) Expression {
+	return UnaryExpr{Op: "not", Expr: Exists(sub)}
+}
+
+// Any compares left to every row returned by sub with op, building
+// "left op any (sub)". It's true when any of the comparisons are.
+func Any(op string, left Expression, sub Statement) Expression {
+	return Predicate(op+" any", left, Wrap(sub))
+}
+
+// All is like Any but is only true when all of the comparisons are, building
+// "left op all (sub)".
+func All(op string, left Expression, sub Statement) Expression {
+	return Predicate(op+" all", left, Wrap(sub))
+}
+
+// Array builds the postgres array literal "array[a, b, c]". Without any
+// elements it builds "array[]", which needs a cast to be typed, like
+// CastOp(Array(), "int[]").
+func Array(elems ...Expression) Expression {
+	me := MultiExpression{
+		Delimeter:   defaultExpressionDelimeter,
+		Expressions: elems,
+	}
+
+	return nodeExpr{exprs: elems, build: func(ctx *BuildContext) string {
+		return "array[" + ctx.Build(me) + "]"
+	}}
+}
+
+// AnyArray checks whether left equals any element of the array arr, building
+// "left = any(arr)". It's the postgres alternative to In that takes a single
+// array, such as Array or a placeholder bound to a slice.
+func AnyArray(left, arr Expression) Expression {
+	return nodeExpr{exprs: []Expression{left, arr}, build: func(ctx *BuildContext) string {
+		return ctx.Build(left) + " = any(" + ctx.Build(arr) + ")"
+	}}
+}
+
+// ScalarSubquery uses sub as a single value, building "(sub)". It can be used
+// as a column or on either side of a comparison.
+func ScalarSubquery(sub Statement) Expression {
+	return Wrap(sub)
+}
+
+// Not negates expr, wrapping it in "()" so the negation applies to the whole
+// expression.
+func Not(expr Expression) Expression {
+	return UnaryExpr{Op: "not", Expr: Wrap(expr)}
+}
+
+// And joins predicates with " and " and wraps them in "()" so they keep their
+// precedence when nested in other predicates.
+func And(predicates ...Expression) Expression {
+	return Wrap(MultiExpression{
+		Delimeter:   " and ",
+		Expressions: predicates,
+	})
+}
+
+// Or joins predicates with " or " and wraps them in "()" so they keep their
+// precedence when nested in other predicates.
+func Or(predicates ...Expression) Expression {
+	return Wrap(MultiExpression{
+		Delimeter:   " or ",
+		Expressions: predicates,
+	})
+}
+
+// Group wraps predicates in their own "()" as a single expression, joining
+// them with " and ". It's the same as And and reads better when it's used to
+// control nesting inside a Where or Or.
+func Group(predicates ...Expression) Expression {
+	return And(predicates...)
+}
+
+// Asc sorts expr in ascending order.
+func Asc(expr Expression) Expression {
+	return Predicate("asc", expr, nil)
+}
+
+// Desc sorts expr in descending order.
+func Desc(expr Expression) Expression {
+	return Predicate("desc", expr, nil)
+}
+
+// NullsFirst sorts nulls before non-null values of expr. It can wrap Asc or
+// Desc: NullsFirst(Desc(Ref("x"))) builds "x desc nulls first".
+func NullsFirst(expr Expression) Expression {
+	return Predicate("nulls first", expr, nil)
+}
+
+// NullsLast sorts nulls after non-null values of expr. It can wrap Asc or Desc:
+// NullsLast(Asc(Ref("x"))) builds "x asc nulls last".
+func NullsLast(expr Expression) Expression {
+	return Predicate("nulls last", expr, nil)
+}
+
+// OrderByCollate sorts col using collation in the direction dir, which is
+// "asc", "desc" or "" for the default, building "col collate "C" asc". Wrap it
+// in NullsFirst or NullsLast to control where nulls are sorted. OrderByCollate
+// panics on any other direction.
+func OrderByCollate(col, collation, dir string) Expression {
+	expr := Collate(Ref(col), collation)
+
+	switch dir {
+	case "":
+		return expr
+	case "asc":
+		return Asc(expr)
+	case "desc":
+		return Desc(expr)
+	}
+
+	panic(fmt.Sprintf("sqlbuilder: invalid sort direction %q", dir))
+}
+
+// Collate builds "expr collate "C"", with collation quoted as an identifier
+// for the dialect being built. It can be used on either side of a comparison,
+// like Equals(Collate(Ref("name"), "C"), Placeholder()).
+func Collate(expr Expression, collation string) Expression {
+	name := ContextExpressionFunc(func(ctx *BuildContext) string {
+		return ctx.Dialect().QuoteIdentifier(collation)
+	})
+
+	return Predicate("collate", expr, name)
+}
+
+// Default builds "default", the default value of a column, for use in Values
+// or Set.
+func Default() Expression {
+	return Ref("default")
+}
+
+func Placeholder() Expression {
+	return PlaceholderExpr{}
+}
+
+// Arg binds value to a placeholder. The value is collected when the statement
+// is built with BuildArgs.
+func Arg(value interface{}) Expression {
+	return ArgExpr{Value: value}
+}
+
+type MultiExpression struct {
+	Delimeter   string
+	Expressions []Expression
+}
+
+func (e MultiExpression) Build() string { return e.BuildWith(&BuildContext{}) }
+
+func (e MultiExpression) BuildWith(ctx *BuildContext) string {
+	if len(e.Expressions) == 1 {
+		return ctx.Build(e.Expressions[0])
+	}
+
+	builder := strings.Builder{}
+
+	for i, expr := range e.Expressions {
+		if i > 0 {
+			builder.WriteString(e.Delimeter)
+		}
+
+		builder.WriteString(ctx.Build(expr))
+	}
+
+	return builder.String()
+}
+
+type SimpleListExpression struct {
+	Delimeter string
+	Values    []string
+}
+
+func (e SimpleListExpression) Build() string {
+	return strings.Join(e.Values, e.Delimeter)
+}
+
+type StatementOption func(*Statement)
+
+// Statement is a sql statement built from its kind, expressions and clauses.
+// Statements are returned as values, but copying one still shares the slices
+// it holds, so applying an option to a copy can change the original. Use Clone
+// to derive a statement from another.
+type Statement struct {
+	Kind        StatementKind
+	Expressions []Expression
+	Clauses     []Clause
+
+	distinct   bool
+	distinctOn []Expression
+	// distinctOnUnchecked skips checking distinctOn against the order by
+	distinctOnUnchecked bool
+	// placeholder overrides the placeholders of the dialect
+	placeholder   string
+	with          []commonTableExpression
+	withRecursive bool
+	err           error
+}
+
+// Clone returns a copy of the statement that shares nothing the statement
+// options change, so options can be applied to the copy without changing s.
+func (s Statement) Clone() Statement {
+	c := s
+	c.Expressions = append([]Expression(nil), s.Expressions...)
+	c.distinctOn = append([]Expression(nil), s.distinctOn...)
+	c.with = append([]commonTableExpression(nil), s.with...)
+	c.Clauses = make([]Clause, len(s.Clauses))
+
+	for i, clause := range s.Clauses {
+		// DoUpdate appends to the assignments of an existing on conflict
+		if oc, ok := clause.(onConflictClause); ok {
+			oc.update = append([]Expression(nil), oc.update...)
+			clause = oc
+		}
+
+		c.Clauses[i] = clause
+	}
+
+	return c
+}
+
+// NumPlaceholders returns the number of placeholders the statement builds.
+func (s Statement) NumPlaceholders() int {
+	ctx := &BuildContext{}
+	s.BuildWith(ctx)
+
+	return len(ctx.params)
+}
+
+// ClausesOfKind returns the clauses of the statement of kind k, in the order
+// they were added. It's meant for code that inspects or rewrites statements,
+// with k one of the exported kinds such as WhereClauseKind.
+func (s Statement) ClausesOfKind(k ClauseKind) []Clause {
+	var clauses []Clause
+
+	for _, clause := range s.Clauses {
+		if clause.Kind() == k {
+			clauses = append(clauses, clause)
+		}
+	}
+
+	return clauses
+}
+
+// AddWhere adds predicates to the statement the same way Where does, for code
+// that rewrites statements, such as to add a "deleted_at is null" filter to
+// every select.
+func (s *Statement) AddWhere(predicates ...Expression) {
+	Where(predicates...)(s)
+}
+
+// Err returns the first error recorded while the statement was put together,
+// such as a nil expression passed to Where. A statement with an error builds
+// as "". The errors of statements nested in it, like subselects, are recorded
+// by it too.
+func (s Statement) Err() error {
+	return s.err
+}
+
+func (s Statement) Build() string {
+	return s.BuildWith(&BuildContext{})
+}
+
+// String returns Build so statements can be printed and logged.
+func (s Statement) String() string {
+	return s.Build()
+}
+
+// BuildArgs builds the statement and returns it along with every argument
+// bound with Arg, in the same order as their placeholders in the SQL. The
+// result can be passed straight to db.Query(sql, args...).
+func (s Statement) BuildArgs() (string, []interface{}) {
+	ctx := &BuildContext{}
+	sql := s.BuildWith(ctx)
+
+	return sql, ctx.Args()
+}
+
+// BuildWithDialect builds the statement for d, numbering or otherwise
+// rewriting every placeholder in the statement tree for that dialect.
+func (s Statement) BuildWithDialect(d Dialect) string {
+	return s.BuildWith(NewBuildContext(d))
+}
+
+// BuildArgsWithDialect is like BuildArgs but builds the statement for d.
+func (s Statement) BuildArgsWithDialect(d Dialect) (string, []interface{}) {
+	ctx := NewBuildContext(d)
+	sql := s.BuildWith(ctx)
+
+	return sql, ctx.Args()
+}
+
+func (s Statement) BuildWith(ctx *BuildContext) string {
+	if s.err != nil {
+		return ""
+	}
+
+	sb := getStatementBuilder()
+	defer putStatementBuilder(sb)
+
+	if s.placeholder != "" {
+		defer func(format string) { ctx.placeholderFormat = format }(ctx.placeholderFormat)
+		ctx.placeholderFormat = s.placeholder
+	}
+
+	buf := &sb.buf
+
+	if len(s.with) > 0 {
+		s.buildWith(ctx, buf)
+	}
+
+	if s.isCompound() {
+		buf.WriteString(s.buildCompound(ctx))
+		buf.WriteByte(' ')
+	} else {
+		s.buildHead(ctx, buf)
+	}
+
+	fetch := ctx.Dialect().Supports(FeatureOffsetFetch)
+
+	for _, clause := range s.Clauses {
+		kind := clause.Kind()
+
+		// every kind of join shares a position so joins are built in the
+		// order they were added to the statement
+		if isJoin(kind) {
+			kind = _JoinClause
+		}
+
+		// offset fetch pagination builds the limit after the offset
+		if fetch && kind == _LimitClause {
+			kind = _FetchClause
+		}
+
+		// a clause kind without a position would be dropped or built under
+		// the wrong keyword, which is worse than failing loudly
+		if kind == _unknownClause || int(kind) >= numClauseKinds {
+			panic(fmt.Sprintf("sqlbuilder: %s has no position in a statement, is clausekind_string.go up to date?", kind))
+		}
+
+		cb := &sb.clauses[kind]
+		if len(cb.clauses) == 0 {
+			cb.delimeter = clause.Delimeter()
+		}
+
+		cb.clauses = append(cb.clauses, clause)
+	}
+
+	// fetch can't be used without an offset
+	if fetch && len(sb.clauses[_FetchClause].clauses) > 0 && len(sb.clauses[_OffsetClause].clauses) == 0 {
+		sb.clauses[_OffsetClause].clauses = append(sb.clauses[_OffsetClause].clauses, offsetClause{start: Ref("0")})
+	}
+
+	// and offset can't be used without an order by, so one that doesn't
+	// change the order is added
+	if fetch && len(sb.clauses[_OffsetClause].clauses) > 0 && len(sb.clauses[_OrderByClause].clauses) == 0 {
+		sb.clauses[_OrderByClause].delimeter = orderByClause{}.Delimeter()
+		sb.clauses[_OrderByClause].clauses = append(sb.clauses[_OrderByClause].clauses, orderByClause{columns: []Expression{Wrap(Ref("select null"))}})
+	}
+
+	// offset fetch pagination builds the offset before the limit, but the
+	// limit is numbered first so LimitP and OffsetP take their args in the
+	// same order for every dialect
+	numberFetch := fetch && len(sb.clauses[_FetchClause].clauses) > 0 && ctx.numberedPlaceholders()
+	var fetchSQL string
+
+	keywords := keywordClauses[s.Kind]
+
+	for kind, group := range sb.clauses {
+		if len(group.clauses) == 0 {
+			continue
+		}
+
+		if ClauseKind(kind) == _OffsetClause && numberFetch {
+			fetchSQL = ctx.Build(sb.clauses[_FetchClause].clauses[0])
+		}
+
+		if ClauseKind(kind) == _FetchClause && fetchSQL != "" {
+			buf.WriteString(fetchSQL)
+			buf.WriteByte(' ')
+
+			continue
+		}
+
+		if keywords[ClauseKind(kind)] {
+			buf.WriteString(ClauseKind(kind).String())
+			buf.WriteByte(' ')
+		}
+
+		for i, clause := range group.clauses {
+			if i > 0 {
+				buf.WriteString(group.delimeter)
+			}
+
+			buf.WriteString(ctx.Build(clause))
+		}
+
+		buf.WriteByte(' ')
+	}
+
+	return strings.TrimSpace(buf.String())
+}
+
+// buildWith writes the common table expressions of the statement to buf.
+func (s Statement) buildWith(ctx *BuildContext, buf *bytes.Buffer) {
+	buf.WriteString("with ")
+
+	if s.withRecursive {
+		buf.WriteString("recursive ")
+	}
+
+	for i, cte := range s.with {
+		if i > 0 {
+			buf.WriteString(defaultExpressionDelimeter)
+		}
+
+		buf.WriteString(ctx.Build(cte))
+	}
+
+	buf.WriteByte(' ')
+}
+
+// buildHead writes the statement keyword and its expressions, such as the
+// columns of a select, to buf.
+func (s Statement) buildHead(ctx *BuildContext, buf *bytes.Buffer) {
+	if s.Kind == _TableStatement && !ctx.Dialect().Supports(FeatureTableStatement) {
+		buf.WriteString("select * from")
+	} else {
+		buf.WriteString(s.Kind.String())
+	}
+
+	buf.WriteByte(' ')
+
+	if s.Kind == _SelectStatement {
+		switch {
+		case len(s.distinctOn) > 0 && ctx.Dialect().Supports(FeatureDistinctOn):
+			on := MultiExpression{
+				Delimeter:   defaultExpressionDelimeter,
+				Expressions: s.distinctOn,
+			}
+
+			buf.WriteString("distinct on ")
+			buf.WriteString(ctx.Build(Wrap(on)))
+			buf.WriteByte(' ')
+		case s.distinct || len(s.distinctOn) > 0:
+			buf.WriteString("distinct ")
+		}
+	}
+
+	for _, expr := range s.Expressions {
+		if expr == nil {
+			continue
+		}
+
+		buf.WriteString(ctx.Build(expr))
+		buf.WriteByte(' ')
+	}
+}
+
+func (s Statement) isCompound() bool {
+	switch s.Kind {
+	case _UnionStatement, _UnionAllStatement, _IntersectStatement, _ExceptStatement,
+		_IntersectAllStatement, _ExceptAllStatement:
+		return true
+	}
+
+	return false
+}
+
+// buildCompound builds the operands of a compound statement, each wrapped in
+// "()" and joined on the compound operator. Operands that are compounds of the
+// same kind, without clauses, common table expressions or a placeholder of
+// their own, are flattened into this one since the result is the same. Except
+// isn't associative, so only its first operand is flattened.
+func (s Statement) buildCompound(ctx *BuildContext) string {
+	operands := make([]string, 0, len(s.Expressions))
+
+	for i, expr := range s.Expressions {
+		flatten := i == 0 || s.Kind != _ExceptStatement && s.Kind != _ExceptAllStatement
+
+		if sub, ok := expr.(Statement); ok && flatten && sub.canFlattenInto(s) {
+			operands = append(operands, sub.buildCompound(ctx))
+			continue
+		}
+
+		operands = append(operands, ctx.Build(Wrap(expr)))
+	}
+
+	return strings.Join(operands, " "+s.Kind.String()+" ")
+}
+
+// canFlattenInto reports whether the operands of s can be built as operands of
+// the compound parent, without anything of s's own being lost.
+func (s Statement) canFlattenInto(parent Statement) bool {
+	return s.Kind == parent.Kind && len(s.Clauses) == 0 && len(s.with) == 0 && s.placeholder == "" && s.err == nil
+}
+
+type commonTableExpression struct {
+	name    string
+	columns []string
+	sub     Statement
+}
+
+// CTEOption modifies a common table expression added with With or
+// WithRecursive.
+type CTEOption func(*commonTableExpression)
+
+// WithColumns names the columns of a common table expression, building
+// "with t(a, b) as (sub)".
+func WithColumns(cols ...string) CTEOption {
+	return func(cte *commonTableExpression) {
+		cte.columns = cols
+	}
+}
+
+func (e commonTableExpression) Build() string { return e.BuildWith(&BuildContext{}) }
+
+func (e commonTableExpression) BuildWith(ctx *BuildContext) string {
+	name := quoteAlias(ctx.Dialect(), e.name)
+
+	if len(e.columns) > 0 {
+		name += "(" + strings.Join(e.columns, defaultExpressionDelimeter) + ")"
+	}
+
+	return name + " as " + ctx.Build(Wrap(e.sub))
+}
+
+func isJoin(kind ClauseKind) bool {
+	switch kind {
+	case _JoinClause, _LeftJoinClause, _InnerJoinClause, _RightJoinClause, _FullJoinClause, _CrossJoinClause:
+		return true
+	}
+
+	return false
+}
+
+// keywordClauses are the clauses, for each kind of statement, that have their
+// keyword written once before every clause of that kind. Other clauses build
+// their own keyword.
+var keywordClauses = map[StatementKind]map[ClauseKind]bool{
+	_SelectStatement: {_FromClause: true, _WhereClause: true, _GroupByClause: true, _HavingClause: true, _WindowClause: true},
+	_UpdateStatement: {_SetClause: true, _UpdateFromClause: true, _WhereClause: true, _ReturningClause: true},
+	_InsertStatement: {_ValuesClause: true, _ReturningClause: true},
+	_DeleteStatement: {_UsingClause: true, _WhereClause: true, _ReturningClause: true},
+}
+
+// clauseBuilder groups the clauses of a single kind in a statement.
+type clauseBuilder struct {
+	delimeter string
+	clauses   []Clause
+}
+
+// statementBuilder holds the buffers used while building a statement. They
+// are pooled and reused since statements are often built in hot paths.
+type statementBuilder struct {
+	buf     bytes.Buffer
+	clauses []clauseBuilder
+}
+
+// numClauseKinds is the number of clause kinds known to the stringer, which is
+// one more than the last kind since it includes _unknownClause.
+var numClauseKinds = len(_ClauseKind_index) - 1
+
+// maxPooledBuffer is the largest buffer put back in the pool, so building the
+// odd huge statement doesn't keep its buffer around forever.
+const maxPooledBuffer = 64 << 10
+
+var statementBuilderPool = sync.Pool{
+	New: func() interface{} {
+		return &statementBuilder{
+			clauses: make([]clauseBuilder, numClauseKinds),
+		}
+	},
+}
+
+func getStatementBuilder() *statementBuilder {
+	return statementBuilderPool.Get().(*statementBuilder)
+}
+
+func putStatementBuilder(sb *statementBuilder) {
+	if sb.buf.Cap() > maxPooledBuffer {
+		return
+	}
+
+	sb.buf.Reset()
+
+	for i := range sb.clauses {
+		group := sb.clauses[i].clauses
+		for j := range group {
+			group[j] = nil
+		}
+
+		sb.clauses[i].clauses = group[:0]
+	}
+
+	statementBuilderPool.Put(sb)
+}
+
+// replaceClause removes any clauses of the same kind as clause before appending
+// it. It's used for clauses that can only show up once in a statement.
+func (s *Statement) replaceClause(clause Clause) {
+	clauses := make([]Clause, 0, len(s.Clauses)+1)
+
+	for _, c := range s.Clauses {
+		if c.Kind() != clause.Kind() {
+			clauses = append(clauses, c)
+		}
+	}
+
+	s.Clauses = append(clauses, clause)
+}
+
+// checkExpressions records an error when any of exprs, or the expressions
+// nested in them, are nil or can't be built, including subselects with an
+// error of their own. Only the first error is kept.
+func (s *Statement) checkExpressions(option string, exprs ...Expression) {
+	if s.err != nil {
+		return
+	}
+
+	for i, expr := range exprs {
+		if err := expressionErr(expr); err != nil {
+			s.err = fmt.Errorf("%w: argument %d of %s", err, i, option)
+			return
+		}
+	}
+}
+
+// expressionErr returns the first problem found walking expr: a nil
+// expression, an erroredExpression or a statement that recorded an error.
+// Nested statements aren't walked into since they've checked their own
+// expressions.
+func expressionErr(expr Expression) error {
+	if isNilExpression(expr) {
+		return ErrNilExpression
+	}
+
+	switch e := expr.(type) {
+	case interface{ Err() error }:
+		return e.Err()
+	case erroredExpression:
+		if err := e.expressionErr(); err != nil {
+			return err
+		}
+	}
+
+	for _, child := range requiredChildren(expr) {
+		if err := expressionErr(child); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// requiredChildren returns the children of expr leaving out the ones that may
+// be nil, like the right side of IsNull or the operand of a searched case.
+func requiredChildren(expr Expression) []Expression {
+	switch e := expr.(type) {
+	case PredicateExpr:
+		if e.Right == nil {
+			return []Expression{e.Left}
+		}
+	case CaseExpression:
+		var exprs []Expression
+
+		for _, child := range children(e) {
+			if !isNilExpression(child) {
+				exprs = append(exprs, child)
+			}
+		}
+
+		return exprs
+	}
+
+	return children(expr)
+}
+
+// erroredExpression is an expression that can't be built because of a
+// problem with how it was put together, recorded by the statement it's used
+// in.
+type erroredExpression interface {
+	expressionErr() error
+}
+
+// isNilExpression reports whether expr is nil, including a nil ExpressionFunc
+// or ContextExpressionFunc.
+func isNilExpression(expr Expression) bool {
+	switch fn := expr.(type) {
+	case nil:
+		return true
+	case ExpressionFunc:
+		return fn == nil
+	case ContextExpressionFunc:
+		return fn == nil
+	}
+
+	return false
+}
+
+// onConflict returns the on conflict clause of the statement, adding one if
+// it doesn't have one yet, and a func to store it back after it's changed.
+func (s *Statement) onConflict() (onConflictClause, func(onConflictClause)) {
+	for i, clause := range s.Clauses {
+		if c, ok := clause.(onConflictClause); ok {
+			return c, func(c onConflictClause) { s.Clauses[i] = c }
+		}
+	}
+
+	return onConflictClause{}, func(c onConflictClause) { s.Clauses = append(s.Clauses, c) }
+}
+
+// locking returns the locking clause of the statement, adding one if it
+// doesn't have one yet, and a func to store it back after it's changed.
+func (s *Statement) locking() (lockingClause, func(lockingClause)) {
+	for i, clause := range s.Clauses {
+		if c, ok := clause.(lockingClause); ok {
+			return c, func(c lockingClause) { s.Clauses[i] = c }
+		}
+	}
+
+	return lockingClause{}, func(c lockingClause) { s.Clauses = append(s.Clauses, c) }
+}
+
+func (s Statement) hasClause(kind ClauseKind) bool {
+	for _, clause := range s.Clauses {
+		if clause.Kind() == kind {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Select takes an expression as the column or columns and 0 or more options
+// that modify the statement object to build the query. A nil columns
+// expression selects "*".
+func Select(columns Expression, opts ...StatementOption) Statement {
+	if columns == nil {
+		columns = Ref("*")
+	}
+
+	st := Statement{
+		Kind:        _SelectStatement,
+		Expressions: []Expression{columns},
+	}
+
+	st.checkExpressions("columns", columns)
+
+	for _, opt := range opts {
+		opt(&st)
+	}
+
+	return st
+}
+
+// SelectStar is shorthand for Select(Ref("*"), opts...).
+func SelectStar(opts ...StatementOption) Statement {
+	return Select(nil, opts...)
+}
+
+// Update takes a table expression and 0 or more options that modify the
+// statement object to build the query. An update must have at least one Set
+// option with assignments. Without one the statement records an error wrapping
+// ErrMissingClause rather than building "update t where ...", which is invalid
+// SQL.
+func Update(table Expression, opts ...StatementOption) Statement {
+	st := Statement{
+		Kind:        _UpdateStatement,
+		Expressions: []Expression{table},
+	}
+
+	st.checkExpressions("table", table)
+
+	for _, opt := range opts {
+		opt(&st)
+	}
+
+	if st.err == nil && !st.hasClause(_SetClause) {
+		st.err = fmt.Errorf("%w: update statement requires set", ErrMissingClause)
+	}
+
+	return st
+}
+
+// Insert takes a table expression and 0 or more options that modify the
+// statement object to build the query. Use InsertColumns and Values to set what
+// is inserted.
+func Insert(table Expression, opts ...StatementOption) Statement {
+	st := Statement{
+		Kind:        _InsertStatement,
+		Expressions: []Expression{table},
+	}
+
+	st.checkExpressions("table", table)
+
+	for _, opt := range opts {
+		opt(&st)
+	}
+
+	return st
+}
+
+// Delete takes a table expression and 0 or more options that modify the
+// statement object to build the query. Without a Where option every row in
+// the table is deleted.
+func Delete(table Expression, opts ...StatementOption) Statement {
+	st := Statement{
+		Kind:        _DeleteStatement,
+		Expressions: []Expression{table},
+	}
+
+	st.checkExpressions("table", table)
+
+	for _, opt := range opts {
+		opt(&st)
+	}
+
+	return st
+}
+
+// Truncate empties tables, building "truncate table t". Like the columns of
+// Select, several tables can be truncated at once with Columns, building
+// "truncate table a, b".
+func Truncate(tables Expression, opts ...StatementOption) Statement {
+	st := Statement{
+		Kind:        _TruncateStatement,
+		Expressions: []Expression{tables},
+	}
+
+	st.checkExpressions("table", tables)
+
+	for _, opt := range opts {
+		opt(&st)
+	}
+
+	return st
+}
+
+// TableStmt selects every row and column of table, building the postgres
+// shorthand "table t". Dialects without it build "select * from t" instead.
+// It can be used anywhere a select can, such as in Union or FromSubselect.
+func TableStmt(table Expression, opts ...StatementOption) Statement {
+	st := Statement{
+		Kind:        _TableStatement,
+		Expressions: []Expression{table},
+	}
+
+	st.checkExpressions("table", table)
+
+	for _, opt := range opts {
+		opt(&st)
+	}
+
+	return st
+}
+
+// RestartIdentity resets the sequences owned by the columns of truncated
+// tables, building "truncate table t restart identity".
+func RestartIdentity() StatementOption {
+	return func(st *Statement) {
+		st.replaceClause(keywordClause{kind: _RestartIdentityClause})
+	}
+}
+
+// Cascade also truncates tables with foreign keys to the truncated tables,
+// building "truncate table t cascade".
+func Cascade() StatementOption {
+	return func(st *Statement) {
+		st.replaceClause(keywordClause{kind: _CascadeClause})
+	}
+}
+
+// Union combines the results of a and b, removing duplicate rows, building
+// "(a) union (b)". The options modify the compound statement as a whole, so
+// OrderBy, Limit and Offset apply to the combined result rather than b.
+func Union(a, b Statement, opts ...StatementOption) Statement {
+	return compound(_UnionStatement, a, b, opts)
+}
+
+// UnionAll is like Union but keeps duplicate rows.
+func UnionAll(a, b Statement, opts ...StatementOption) Statement {
+	return compound(_UnionAllStatement, a, b, opts)
+}
+
+// Intersect returns the rows that are in the results of both a and b.
+func Intersect(a, b Statement, opts ...StatementOption) Statement {
+	return compound(_IntersectStatement, a, b, opts)
+}
+
+// Except returns the rows in the results of a that aren't in the results of b.
+func Except(a, b Statement, opts ...StatementOption) Statement {
+	return compound(_ExceptStatement, a, b, opts)
+}
+
+// IntersectAll is like Intersect but keeps duplicate rows.
+func IntersectAll(a, b Statement, opts ...StatementOption) Statement {
+	return compound(_IntersectAllStatement, a, b, opts)
+}
+
+// ExceptAll is like Except but keeps duplicate rows.
+func ExceptAll(a, b Statement, opts ...StatementOption) Statement {
+	return compound(_ExceptAllStatement, a, b, opts)
+}
+
+func compound(kind StatementKind, a, b Statement, opts []StatementOption) Statement {
+	st := Statement{
+		Kind:        kind,
+		Expressions: []Expression{a, b},
+	}
+
+	st.checkExpressions("compound", a, b)
+
+	for _, opt := range opts {
+		opt(&st)
+	}
+
+	return st
+}
+
+// With adds sub as a common table expression named name, building
+// "with name as (sub)" before the statement. Multiple uses of this
+// StatementOption are joined on ", " under a single "with".
+func With(name string, sub Statement, opts ...CTEOption) StatementOption {
+	return func(st *Statement) {
+		st.checkExpressions("with", sub)
+
+		cte := commonTableExpression{name: name, sub: sub}
+
+		for _, opt := range opts {
+			opt(&cte)
+		}
+
+		st.with = append(st.with, cte)
+	}
+}
+
+// WithRecursive is like With but builds "with recursive", allowing sub to
+// reference itself by name. Since "recursive" applies to the whole with list,
+// using it once makes every common table expression in the statement
+// recursive.
+func WithRecursive(name string, sub Statement, opts ...CTEOption) StatementOption {
+	return func(st *Statement) {
+		With(name, sub, opts...)(st)
+		st.withRecursive = true
+	}
+}
+
+// Distinct makes a select statement only return distinct rows.
+func Distinct() StatementOption {
+	return func(st *Statement) {
+		st.distinct = true
+	}
+}
+
+// DistinctOn makes a select statement return the first row of each set of rows
+// where cols are equal, building "select distinct on (cols) ...". This is
+// postgres specific; dialects that don't support it fall back to a plain
+// "select distinct".
+//
+// Validate requires the order by of the select to start with cols, since
+// which row of each set is returned is otherwise unpredictable. Use
+// UncheckedDistinctOn to skip the check.
+func DistinctOn(cols ...Expression) StatementOption {
+	return func(st *Statement) {
+		st.checkExpressions("distinct on", cols...)
+
+		st.distinctOn = cols
+	}
+}
+
+// UncheckedDistinctOn is DistinctOn without Validate checking that the order
+// by starts with cols, for when any row of each set will do.
+func UncheckedDistinctOn(cols ...Expression) StatementOption {
+	return func(st *Statement) {
+		DistinctOn(cols...)(st)
+		st.distinctOnUnchecked = true
+	}
+}
+
+// From takes a list of expressions to use as a TableExpression list for the
+// sql-from clause. The list is joined in argument order on ", ".
+func From(tables ...Expression) StatementOption {
+	return func(st *Statement) {
+		st.checkExpressions("from", tables...)
+
+		st.Clauses = append(st.Clauses, fromClause{kind: _FromClause, tables: tables})
+	}
+}
+
+// Tables adds tables to the sql-from clause like From, building
+// "from a, b, c", but makes sure the list is usable. A nil table records an
+// error wrapping ErrNilExpression, and passing no tables at all one wrapping
+// ErrEmptyClause.
+func Tables(tables ...Expression) StatementOption {
+	return func(st *Statement) {
+		if len(tables) == 0 && st.err == nil {
+			st.err = fmt.Errorf("%w: tables needs at least one table", ErrEmptyClause)
+		}
+
+		From(tables...)(st)
+	}
+}
+
+// FromFunc adds a table valued function to the sql-from clause, building
+// "fn as alias(cols)" such as "unnest(?) as t(id)". Without cols it builds
+// "fn as alias". The alias is quoted when it needs to be, like it is by As.
+func FromFunc(fn Expression, alias string, cols ...string) StatementOption {
+	return From(AliasExpr{Expr: fn, Alias: alias, Columns: cols})
+}
+
+// ValuesTable builds rows as an inline table for use in From or a join,
+// building "(values (1, 'a'), (2, 'b')) as t(id, name)". When the rows don't
+// all have the same number of values the statement it's used in records an
+// error wrapping ErrRowLength, and when there are no rows one wrapping
+// ErrEmptyClause.
+func ValuesTable(rows [][]Expression, as string, cols ...string) Expression {
+	t := valuesTable{as: as, cols: cols}
+
+	if len(rows) == 0 {
+		t.err = fmt.Errorf("%w: values table %s has no rows", ErrEmptyClause, as)
+		return t
+	}
+
+	t.rows = make([]Expression, len(rows))
+
+	for i, row := range rows {
+		if len(row) != len(rows[0]) {
+			t.err = fmt.Errorf("%w: values row %d has %d values, expected %d", ErrRowLength, i, len(row), len(rows[0]))
+			return t
+		}
+
+		t.rows[i] = Wrap(Columns(row...))
+	}
+
+	return t
+}
+
+type valuesTable struct {
+	rows []Expression
+	as   string
+	cols []string
+	err  error
+}
+
+func (t valuesTable) Build() string { return t.BuildWith(&BuildContext{}) }
+
+func (t valuesTable) BuildWith(ctx *BuildContext) string {
+	if t.err != nil {
+		return ""
+	}
+
+	s := "(values " + ctx.Build(Columns(t.rows...)) + ") as " + quoteAlias(ctx.Dialect(), t.as)
+
+	if len(t.cols) > 0 {
+		s += "(" + strings.Join(t.cols, defaultExpressionDelimeter) + ")"
+	}
+
+	return s
+}
+
+func (t valuesTable) Children() []Expression { return t.rows }
+
+func (t valuesTable) expressionErr() error { return t.err }
+
+// BulkUpdate updates many rows of table at once from rows of values, one row
+// per updated row, building
+// "update t set a = _bulk.a from (values (?, ?), (?, ?)) as _bulk(id, a) where (t.id = _bulk.id)".
+// The values of each row are given in the order of cols, which must include
+// keyCol to match rows of the table with rows of values; every other column
+// in cols is set. Values are bound to placeholders in row order, except for
+// values that are already an Expression, which are used as they are. Postgres
+// types the placeholders in values as text when it can't infer them, so cast
+// the values of the first row, which type the columns for every row, such as
+// CastOp(Arg(1), "int").
+//
+// When rows don't all have a value for each of cols, the statement records an
+// error wrapping ErrRowLength. An error wrapping ErrInvalidClause is recorded
+// when keyCol isn't in cols, and one wrapping ErrMissingClause when there
+// are no rows or nothing to set.
+func BulkUpdate(table, keyCol string, cols []string, rows [][]interface{}) Statement {
+	fail := func(err error) Statement {
+		return Statement{
+			Kind:        _UpdateStatement,
+			Expressions: []Expression{Ref(table)},
+			err:         err,
+		}
+	}
+
+	if len(rows) == 0 {
+		return fail(fmt.Errorf("%w: bulk update has no rows", ErrMissingClause))
+	}
+
+	values := make([][]Expression, len(rows))
+
+	for i, row := range rows {
+		if len(row) != len(cols) {
+			return fail(fmt.Errorf("%w: row %d has %d values, expected %d", ErrRowLength, i, len(row), len(cols)))
+		}
+
+		values[i] = make([]Expression, len(row))
+
+		for j, v := range row {
+			if expr, ok := v.(Expression); ok {
+				values[i][j] = expr
+				continue
+			}
+
+			values[i][j] = Arg(v)
+		}
+	}
+
+	var (
+		assignments []Expression
+		hasKey      bool
+	)
+
+	for _, col := range cols {
+		if col == keyCol {
+			hasKey = true
+			continue
+		}
+
+		assignments = append(assignments, Equals(Ref(col), Ref("_bulk."+col)))
+	}
+
+	if !hasKey {
+		return fail(fmt.Errorf("%w: bulk update key column %s isn't one of its columns", ErrInvalidClause, keyCol))
+	}
+
+	if len(assignments) == 0 {
+		return fail(fmt.Errorf("%w: bulk update has no columns to set", ErrMissingClause))
+	}
+
+	return Update(Ref(table),
+		Set(assignments...),
+		UpdateFrom(ValuesTable(values, "_bulk", cols...)),
+		Where(Equals(Ref(table+"."+keyCol), Ref("_bulk."+keyCol))),
+	)
+}
+
+// UpdateFrom joins tables into an update statement, building
+// "update a set ... from b where ...". Columns of tables can be used in the
+// set and where clauses.
+func UpdateFrom(tables ...Expression) StatementOption {
+	return func(st *Statement) {
+		st.checkExpressions("from", tables...)
+
+		st.Clauses = append(st.Clauses, fromClause{kind: _UpdateFromClause, tables: tables})
+	}
+}
+
+// Using joins tables into a delete statement, building
+// "delete from a using b where ...". Columns of tables can be used in the
+// where clause.
+func Using(tables ...Expression) StatementOption {
+	return func(st *Statement) {
+		st.checkExpressions("using", tables...)
+
+		st.Clauses = append(st.Clauses, fromClause{kind: _UsingClause, tables: tables})
+	}
+}
+
+// FromSubselect takes a Statement and an optional as and returns it, wrapped in
+// (), to the sql-from clause. With cols the columns of the subselect are
+// renamed too, building "(sub) as t(a, b)". When the number of columns sub
+// selects is known and doesn't match cols, or cols are given without as, the
+// statement records an error wrapping ErrInvalidClause.
+func FromSubselect(sub Statement, as string, cols ...string) StatementOption {
+	if len(cols) == 0 {
+		return From(subselect(sub, as))
+	}
+
+	return func(st *Statement) {
+		if st.err == nil {
+			if as == "" {
+				st.err = fmt.Errorf("%w: subselect column aliases need a table alias", ErrInvalidClause)
+			} else if n, ok := sub.columnCount(); ok && n != len(cols) {
+				st.err = fmt.Errorf("%w: subselect %s has %d column aliases but selects %d",
+					ErrInvalidClause, as, len(cols), n)
+			}
+		}
+
+		From(AliasExpr{Expr: Wrap(sub), Alias: as, Columns: cols})(st)
+	}
+}
+
+// subselect wraps sub in "()" and aliases it with as when it isn't empty.
+func subselect(sub Statement, as string) Expression {
+	var expr Expression = Wrap(sub)
+	if as != "" {
+		expr = As(expr, as)
+	}
+
+	return expr
+}
+
+func Join(table Expression, predicates ...Expression) StatementOption {
+	return join(_JoinClause, table, predicates)
+}
+
+func LeftJoin(table Expression, predicates ...Expression) StatementOption {
+	return join(_LeftJoinClause, table, predicates)
+}
+
+func InnerJoin(table Expression, predicates ...Expression) StatementOption {
+	return join(_InnerJoinClause, table, predicates)
+}
+
+func RightJoin(table Expression, predicates ...Expression) StatementOption {
+	return join(_RightJoinClause, table, predicates)
+}
+
+func FullJoin(table Expression, predicates ...Expression) StatementOption {
+	return join(_FullJoinClause, table, predicates)
+}
+
+// JoinUsing joins table on the columns named in cols, which must exist with
+// the same name on both sides, building "join table using (cols)".
+func JoinUsing(table Expression, cols ...string) StatementOption {
+	return func(st *Statement) {
+		st.checkExpressions("join", table)
+
+		st.Clauses = append(st.Clauses, joinClause{
+			kind:  _JoinClause,
+			table: table,
+			using: cols,
+		})
+	}
+}
+
+// CrossJoin joins every row of table to every row of the rest of the from
+// list, building "cross join table" without an "on".
+func CrossJoin(table Expression) StatementOption {
+	return join(_CrossJoinClause, table, nil)
+}
+
+// JoinSubselect joins the results of sub aliased as as, building
+// "join (sub) as x on ...". The predicates can reference columns of sub
+// through the alias.
+func JoinSubselect(sub Statement, as string, predicates ...Expression) StatementOption {
+	return join(_JoinClause, subselect(sub, as), predicates)
+}
+
+// LeftJoinSubselect is like JoinSubselect but builds a left join.
+func LeftJoinSubselect(sub Statement, as string, predicates ...Expression) StatementOption {
+	return join(_LeftJoinClause, subselect(sub, as), predicates)
+}
+
+// JoinLateral is like JoinSubselect but builds "join lateral (sub) as x on
+// ...", letting sub reference columns of the tables before it. Without any
+// predicates it joins "on true".
+func JoinLateral(sub Statement, as string, predicates ...Expression) StatementOption {
+	return lateral(_JoinClause, sub, as, predicates)
+}
+
+// LeftJoinLateral is like JoinLateral but builds a left join.
+func LeftJoinLateral(sub Statement, as string, predicates ...Expression) StatementOption {
+	return lateral(_LeftJoinClause, sub, as, predicates)
+}
+
+func lateral(kind ClauseKind, sub Statement, as string, predicates []Expression) StatementOption {
+	if len(predicates) == 0 {
+		predicates = []Expression{Ref("true")}
+	}
+
+	return join(kind, UnaryExpr{Op: "lateral", Expr: subselect(sub, as)}, predicates)
+}
+
+// join adds a join of kind to the statement. All joins are built in the order
+// they're added, regardless of kind.
+func join(kind ClauseKind, table Expression, predicates []Expression) StatementOption {
+	return func(st *Statement) {
+		st.checkExpressions(kind.String(), append([]Expression{table}, predicates...)...)
+
+		st.Clauses = append(st.Clauses, joinClause{
+			kind:       kind,
+			table:      table,
+			predicates: predicates,
+		})
+	}
+}
+
+// Where takes a list of expressions that are expected to be predicates of some
+// kind. These are then join with " and " and wrapped in "()". Multiple uses of
+// this StatementOption will only result in a single "where" clause with each
+// distinct group of predicates wrapped in their own "()" and join with " and ".
+// Where with no predicates adds nothing to the statement.
+func Where(predicates ...Expression) StatementOption {
+	return func(st *Statement) {
+		st.checkExpressions("where", predicates...)
+
+		if len(predicates) == 0 {
+			return
+		}
+
+		me := MultiExpression{
+			Delimeter:   " and ",
+			Expressions: predicates,
+		}
+
+		st.Clauses = append(st.Clauses, whereClause{predicates: me})
+	}
+}
+
+// WhereAll is like Where but skips any nil predicates, for filters that are
+// built up dynamically where some of them may be absent. When every predicate
+// is nil it adds nothing to the statement.
+func WhereAll(predicates []Expression) StatementOption {
+	present := make([]Expression, 0, len(predicates))
+
+	for _, pred := range predicates {
+		if !isNilExpression(pred) {
+			present = append(present, pred)
+		}
+	}
+
+	return Where(present...)
+}
+
+// Having takes a list of predicates to filter groups with. It works the same
+// way as Where: predicates are joined with " and " and wrapped in "()",
+// multiple uses result in a single "having" clause and Having with no
+// predicates adds nothing to the statement.
+func Having(predicates ...Expression) StatementOption {
+	return func(st *Statement) {
+		st.checkExpressions("having", predicates...)
+
+		if len(predicates) == 0 {
+			return
+		}
+
+		me := MultiExpression{
+			Delimeter:   " and ",
+			Expressions: predicates,
+		}
+
+		st.Clauses = append(st.Clauses, havingClause{predicates: me})
+	}
+}
+
+// Set takes a list of assignment expressions, usually built with Equals, for
+// the sql-set clause of an update statement. Multiple uses of this
+// StatementOption result in a single "set" clause with every assignment joined
+// on ", ". Set with no assignments adds nothing to the statement.
+func Set(assignments ...Expression) StatementOption {
+	return func(st *Statement) {
+		st.checkExpressions("set", assignments...)
+
+		if len(assignments) == 0 {
+			return
+		}
+
+		st.Clauses = append(st.Clauses, setClause{assignments: assignments})
+	}
+}
+
+// AssignRow is an assignment for Set that sets every one of cols from the
+// single row returned by sub, building "(a, b) = (select x, y ...)".
+func AssignRow(cols []string, sub Statement) Expression {
+	return Equals(Wrap(Columns(refs(cols)...)), Wrap(sub))
+}
+
+// InsertColumns sets the columns an insert statement sets values for. Using
+// InsertColumns more than once replaces the previous columns.
+func InsertColumns(cols ...string) StatementOption {
+	return func(st *Statement) {
+		st.replaceClause(insertColumnsClause{columns: cols})
+	}
+}
+
+// Values adds a row of values to an insert statement, building
+// "values (a, b)". Multiple uses of this StatementOption insert multiple rows,
+// building "values (a, b), (c, d)".
+func Values(vals ...Expression) StatementOption {
+	return func(st *Statement) {
+		st.checkExpressions("values", vals...)
+
+		st.Clauses = append(st.Clauses, valuesClause{values: vals})
+	}
+}
+
+// BulkValues adds a row of values to an insert statement for each of rows,
+// binding every value to a placeholder in row-major order, building
+// "values (?, ?), (?, ?)". When rows don't all have the same number of values
+// the statement records an error wrapping ErrRowLength.
+//
+// Databases limit how many placeholders a statement can have, such as
+// PostgresMaxPlaceholders. Use NumPlaceholders to check a statement and split
+// rows across several inserts when it's over.
+func BulkValues(rows [][]interface{}) StatementOption {
+	return func(st *Statement) {
+		for i, row := range rows {
+			if len(row) != len(rows[0]) {
+				if st.err == nil {
+					st.err = fmt.Errorf("%w: row %d has %d values, expected %d", ErrRowLength, i, len(row), len(rows[0]))
+				}
+
+				return
+			}
+		}
+
+		for _, row := range rows {
+			vals := make([]Expression, len(row))
+
+			for i, v := range row {
+				vals[i] = Arg(v)
+			}
+
+			st.Clauses = append(st.Clauses, valuesClause{values: vals})
+		}
+	}
+}
+
+// DefaultValues inserts a single row made of every column's default value,
+// building "insert into t default values". It can't be used with Values or
+// InsertColumns.
+func DefaultValues() StatementOption {
+	return func(st *Statement) {
+		st.replaceClause(defaultValuesClause{})
+	}
+}
+
+// OnConflict makes an insert statement handle rows that conflict with an
+// existing row on cols, building "on conflict (cols)". It's followed by either
+// DoUpdate or DoNothing to choose what happens to the conflicting row.
+func OnConflict(cols ...string) StatementOption {
+	return func(st *Statement) {
+		c, store := st.onConflict()
+		c.target = cols
+		store(c)
+	}
+}
+
+// DoUpdate updates the conflicting row of an OnConflict with assignments,
+// building "do update set a = ?". The row that would have been inserted can be
+// referenced with Excluded.
+func DoUpdate(assignments ...Expression) StatementOption {
+	return func(st *Statement) {
+		c, store := st.onConflict()
+		c.update = append(c.update, assignments...)
+		c.doNothing = false
+		store(c)
+	}
+}
+
+// DoUpdateAll updates each of cols in the conflicting row to the value that
+// would have been inserted, building "do update set a = excluded.a, b =
+// excluded.b".
+func DoUpdateAll(cols ...string) StatementOption {
+	assignments := make([]Expression, len(cols))
+
+	for i, col := range cols {
+		assignments[i] = Equals(Ref(col), Excluded(col))
+	}
+
+	return DoUpdate(assignments...)
+}
+
+// Excluded refers to col of the row that would have been inserted in a
+// DoUpdate, building "excluded.col". With MySQL it builds "values(col)".
+func Excluded(col string) ContextExpressionFunc {
+	return func(ctx *BuildContext) string {
+		if ctx.Dialect().Supports(FeatureOnDuplicateKeyUpdate) {
+			return "values(" + col + ")"
+		}
+
+		return "excluded." + col
+	}
+}
+
+// DoNothing skips inserting rows that conflict, building "do nothing". MySQL
+// has no equivalent; use an insert ignore there instead.
+func DoNothing() StatementOption {
+	return func(st *Statement) {
+		c, store := st.onConflict()
+		c.update = nil
+		c.doNothing = true
+		store(c)
+	}
+}
+
+// OnDuplicateKeyUpdate is the MySQL flavor of OnConflict and DoUpdate. With
+// the MySQL dialect an on conflict update builds as
+// "on duplicate key update a = ?"; with other dialects it's the same as
+// DoUpdate without a conflict target.
+func OnDuplicateKeyUpdate(assignments ...Expression) StatementOption {
+	return DoUpdate(assignments...)
+}
+
+// ForUpdate locks the rows returned by a select, building "for update". The
+// locking clause is always built last, after any order by, limit or offset.
+func ForUpdate() StatementOption {
+	return lockStrength("update")
+}
+
+// ForShare locks the rows returned by a select in share mode, building
+// "for share".
+func ForShare() StatementOption {
+	return lockStrength("share")
+}
+
+// ForUpdateOf is like ForUpdate but only locks rows from tables, building
+// "for update of a, b".
+func ForUpdateOf(tables ...string) StatementOption {
+	return func(st *Statement) {
+		c, store := st.locking()
+		c.strength = "update"
+		c.tables = tables
+		store(c)
+	}
+}
+
+// SkipLocked skips rows that are already locked instead of waiting for them,
+// building "for update skip locked". Without ForShare it locks for update.
+func SkipLocked() StatementOption {
+	return lockWait("skip locked")
+}
+
+// NoWait makes the statement fail instead of waiting for locked rows,
+// building "for update nowait". Without ForShare it locks for update.
+func NoWait() StatementOption {
+	return lockWait("nowait")
+}
+
+func lockStrength(strength string) StatementOption {
+	return func(st *Statement) {
+		c, store := st.locking()
+		c.strength = strength
+		store(c)
+	}
+}
+
+func lockWait(wait string) StatementOption {
+	return func(st *Statement) {
+		c, store := st.locking()
+		c.wait = wait
+		store(c)
+	}
+}
+
+// Returning adds a returning clause to an insert, update or delete statement
+// so the affected rows are returned, building "returning a, b". Multiple uses
+// of this StatementOption are joined on ", ". Used on a select, the statement
+// records an error wrapping ErrInvalidClause.
+func Returning(cols ...Expression) StatementOption {
+	return func(st *Statement) {
+		st.checkExpressions("returning", cols...)
+
+		if st.Kind == _SelectStatement {
+			if st.err == nil {
+				st.err = fmt.Errorf("%w: returning is not valid for select statements", ErrInvalidClause)
+			}
+
+			return
+		}
+
+		st.Clauses = append(st.Clauses, returningClause{columns: cols})
+	}
+}
+
+// OrderBy takes a list of column names and adds an order by clause to the
+// statement. It's shorthand for OrderByExpr with each column as a Ref.
+func OrderBy(cols ...string) StatementOption {
+	return OrderByExpr(refs(cols)...)
+}
+
+// OrderByExpr takes a list of expressions and adds an order by clause to the
+// statement. Use Asc, Desc, NullsFirst and NullsLast to control the sort
+// direction of each expression. Multiple uses of this StatementOption sort by
+// every expression, in the order they were added.
+func OrderByExpr(cols ...Expression) StatementOption {
+	return func(st *Statement) {
+		st.checkExpressions("order by", cols...)
+
+		for i, clause := range st.Clauses {
+			if c, ok := clause.(orderByClause); ok {
+				// copy the columns so clones don't share them
+				c.columns = append(c.columns[:len(c.columns):len(c.columns)], cols...)
+				st.Clauses[i] = c
+
+				return
+			}
+		}
+
+		st.Clauses = append(st.Clauses, orderByClause{columns: cols})
+	}
+}
+
+// OrderByOrdinal adds an order by clause sorting on the columns of the select
+// list at positions, counting from 1, building "order by 1, 2". Wrap it with
+// Asc or Desc by using Ordinal with OrderByExpr instead. OrderByOrdinal panics
+// when a position is less than 1.
+func OrderByOrdinal(positions ...int) StatementOption {
+	cols := make([]Expression, len(positions))
+
+	for i, pos := range positions {
+		cols[i] = Ordinal(pos)
+	}
+
+	return OrderByExpr(cols...)
+}
+
+// Ordinal refers to the column of the select list at pos, counting from 1,
+// for use in OrderByExpr. Ordinal panics when pos is less than 1.
+func Ordinal(pos int) Expression {
+	if pos < 1 {
+		panic(fmt.Sprintf("sqlbuilder: invalid column position %d", pos))
+	}
+
+	return Ref(strconv.Itoa(pos))
+}
+
+// GroupBy takes a list of column names and adds a group by clause to the
+// statement. It's shorthand for GroupByExpr with each column as a Ref.
+func GroupBy(cols ...string) StatementOption {
+	return GroupByExpr(refs(cols)...)
+}
+
+// GroupByExpr takes a list of expressions and adds a group by clause to the
+// statement, such as "group by date_trunc('day', created_at)". Multiple uses
+// of this StatementOption, or any of the other group by options, result in a
+// single "group by" clause with every grouping joined on ", ".
+func GroupByExpr(exprs ...Expression) StatementOption {
+	return func(st *Statement) {
+		st.checkExpressions("group by", exprs...)
+
+		st.Clauses = append(st.Clauses, groupByClause{columns: exprs})
+	}
+}
+
+// GroupByRollup groups by cols and each of their prefixes, adding subtotal
+// rows, building "group by rollup (a, b)".
+func GroupByRollup(cols ...string) StatementOption {
+	return func(st *Statement) {
+		st.Clauses = append(st.Clauses, groupByClause{columns: refs(cols), grouping: "rollup"})
+	}
+}
+
+// GroupByCube groups by every combination of cols, building
+// "group by cube (a, b)".
+func GroupByCube(cols ...string) StatementOption {
+	return func(st *Statement) {
+		st.Clauses = append(st.Clauses, groupByClause{columns: refs(cols), grouping: "cube"})
+	}
+}
+
+// GroupingSets groups by each set of columns in turn, building
+// "group by grouping sets ((a, b), (a), ())". An empty set groups every row
+// together for a grand total.
+func GroupingSets(sets ...[]string) StatementOption {
+	return func(st *Statement) {
+		st.Clauses = append(st.Clauses, groupByClause{sets: sets, grouping: "grouping sets"})
+	}
+}
+
+// Comment tags the statement with a trailing comment made of the key value
+// pairs in tags, sorted by key, building "select ... /* a='1',b='2' */". It's
+// useful to tie queries in database logs back to the code that ran them. Using
+// Comment more than once replaces the previous comment.
+func Comment(tags map[string]string) StatementOption {
+	return func(st *Statement) {
+		st.replaceClause(commentClause{tags: tags})
+	}
+}
+
+// NamedWindow adds a window built from spec to the window clause of a select,
+// building "window name as (partition by a order by b)" after any having. Use
+// it from Window with UseWindow to share a spec between columns.
+func NamedWindow(name string, spec ...Expression) StatementOption {
+	return func(st *Statement) {
+		st.checkExpressions("window", spec...)
+
+		st.Clauses = append(st.Clauses, windowClause{
+			name: name,
+			spec: MultiExpression{
+				Delimeter:   " ",
+				Expressions: spec,
+			},
+		})
+	}
+}
+
+// WithPlaceholder overrides the placeholder used by Placeholder, Arg and the
+// like for this statement, whatever dialect it's built for. Any "%d" in
+// placeholder is replaced with the number of the placeholder, counting from 1,
+// so WithPlaceholder("$%d") builds "$1, $2, ...".
+//
+// Subselects and other statements nested in this one use the placeholder too,
+// unless they have one of their own, and are numbered along with the rest of
+// the statement.
+func WithPlaceholder(placeholder string) StatementOption {
+	return func(st *Statement) {
+		st.placeholder = placeholder
+	}
+}
+
+// Limit adds a limit clause with n as the row count. Using Limit more than once
+// replaces the previous limit.
+func Limit(n int) StatementOption {
+	return func(st *Statement) {
+		st.replaceClause(limitClause{count: Ref(strconv.Itoa(n))})
+	}
+}
+
+// LimitP is like Limit but uses a placeholder for the row count so it can be
+// bound as a parameter. With OffsetP the limit is always bound first, even for
+// dialects that build the offset first, such as SQLServer building
+// "offset @p2 rows fetch next @p1 rows only", so the args are passed in the
+// same order for every dialect.
+func LimitP() StatementOption {
+	return func(st *Statement) {
+		st.replaceClause(limitClause{count: Placeholder()})
+	}
+}
+
+// Offset adds an offset clause that skips n rows. Using Offset more than once
+// replaces the previous offset. Offset can be used without Limit, building
+// "offset n" on its own; this is valid for postgres, but sqlite and mysql
+// require a limit to go along with it.
+func Offset(n int) StatementOption {
+	return func(st *Statement) {
+		st.replaceClause(offsetClause{start: Ref(strconv.Itoa(n))})
+	}
+}
+
+// OffsetP is like Offset but uses a placeholder for the number of rows to skip
+// so it can be bound as a parameter.
+func OffsetP() StatementOption {
 	return func(st *Statement) {
-		st.Clauses = append(st.Clauses, groupByClause{columns: cols})
+		st.replaceClause(offsetClause{start: Placeholder()})
 	}
 }
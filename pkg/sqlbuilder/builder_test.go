@@ -1,8 +1,12 @@
 package sqlbuilder
 
 import (
+	"errors"
 	"fmt"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/matryer/is"
 )
@@ -37,7 +41,7 @@ func TestSelect(t *testing.T) {
 		},
 		{
 			description: "simple select with is null and is not null",
-			expected:    "select i.id from items as 'i' where (i.title is not null and i.content is null) order by i.created_at",
+			expected:    "select i.id from items as i where (i.title is not null and i.content is null) order by i.created_at",
 			statement: Select(
 				Columns(Ref("i.id")),
 				From(RefAs("items", "i")),
@@ -50,7 +54,7 @@ func TestSelect(t *testing.T) {
 		},
 		{
 			description: "simple select with column funcions",
-			expected:    "select id, coalesce(title, 'no title') as 'title' from items where (id = ?) order by created_at",
+			expected:    "select id, coalesce(title, 'no title') as title from items where (id = ?) order by created_at",
 			statement: Select(
 				Columns(
 					Ref("id"),
@@ -61,6 +65,92 @@ func TestSelect(t *testing.T) {
 				OrderBy("created_at"),
 			),
 		},
+		{
+			description: "limit and offset",
+			expected:    "select * from items order by created_at limit 10 offset 20",
+			statement: Select(
+				Columns(Ref("*")),
+				Offset(20),
+				From(Ref("items")),
+				Limit(10),
+				OrderBy("created_at"),
+			),
+		},
+		{
+			description: "limit and offset placeholders",
+			expected:    "select * from items where (id > ?) limit ? offset ?",
+			statement: Select(
+				Columns(Ref("*")),
+				From(Ref("items")),
+				Where(Greater(Ref("id"), Placeholder())),
+				LimitP(),
+				OffsetP(),
+			),
+		},
+		{
+			description: "offset without limit",
+			expected:    "select * from items offset 5",
+			statement: Select(
+				Columns(Ref("*")),
+				From(Ref("items")),
+				Offset(5),
+			),
+		},
+		{
+			description: "group by with having",
+			expected:    "select user_id, count(*) from items group by user_id having (count(*) > ?) order by user_id",
+			statement: Select(
+				Columns(Ref("user_id"), Func("count", Ref("*"))),
+				From(Ref("items")),
+				OrderBy("user_id"),
+				Having(Greater(Func("count", Ref("*")), Placeholder())),
+				GroupBy("user_id"),
+			),
+		},
+		{
+			description: "multiple having merge",
+			expected:    "select user_id from items where (deleted = ?) group by user_id having (count(*) > ? and max(score) < ?) and (min(score) > ?)",
+			statement: Select(
+				Ref("user_id"),
+				From(Ref("items")),
+				Where(Equals(Ref("deleted"), Placeholder())),
+				GroupBy("user_id"),
+				Having(
+					Greater(Func("count", Ref("*")), Placeholder()),
+					Less(Func("max", Ref("score")), Placeholder()),
+				),
+				Having(Greater(Func("min", Ref("score")), Placeholder())),
+			),
+		},
+		{
+			description: "distinct",
+			expected:    "select distinct user_id, kind from items",
+			statement: Select(
+				Columns(Ref("user_id"), Ref("kind")),
+				From(Ref("items")),
+				Distinct(),
+			),
+		},
+		{
+			description: "distinct on falls back to distinct",
+			expected:    "select distinct user_id, kind from items order by user_id",
+			statement: Select(
+				Columns(Ref("user_id"), Ref("kind")),
+				DistinctOn(Ref("user_id")),
+				From(Ref("items")),
+				OrderBy("user_id"),
+			),
+		},
+		{
+			description: "last limit wins",
+			expected:    "select * from items limit 5",
+			statement: Select(
+				Columns(Ref("*")),
+				From(Ref("items")),
+				Limit(10),
+				Limit(5),
+			),
+		},
 	}
 
 	is := is.New(t)
@@ -71,7 +161,377 @@ func TestSelect(t *testing.T) {
 	}
 }
 
-func BenchmarkStatementBuilder(b *testing.B) {
+func TestUpdate(t *testing.T) {
+	cases := []struct {
+		description string
+		expected    string
+		statement   Statement
+	}{
+		{
+			description: "simple update",
+			expected:    "update items set name = ? where (id = ?)",
+			statement: Update(
+				Ref("items"),
+				Set(Equals(Ref("name"), Placeholder())),
+				Where(Equals(Ref("id"), Placeholder())),
+			),
+		},
+		{
+			description: "multiple set options merge",
+			expected:    "update items set name = ?, title = ?, updated_at = now() where (id = ?)",
+			statement: Update(
+				Ref("items"),
+				Set(
+					Equals(Ref("name"), Placeholder()),
+					Equals(Ref("title"), Placeholder()),
+				),
+				Where(Equals(Ref("id"), Placeholder())),
+				Set(Equals(Ref("updated_at"), Func("now"))),
+			),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			is.Equal(c.expected, c.statement.Build())
+		})
+	}
+}
+
+func TestUpdateWithoutSet(t *testing.T) {
+	is := is.New(t)
+
+	var assignments []Expression
+
+	for _, st := range []Statement{
+		Update(Ref("items"), Where(Equals(Ref("id"), Placeholder()))),
+		Update(Ref("items"), Set(assignments...), Where(Equals(Ref("id"), Placeholder()))),
+	} {
+		is.True(errors.Is(st.Err(), ErrMissingClause))
+		is.Equal("", st.Build())
+	}
+}
+
+func TestAs(t *testing.T) {
+	cases := []struct {
+		description string
+		expected    string
+		expr        Expression
+	}{
+		{
+			description: "plain identifier",
+			expected:    "id as item_id",
+			expr:        RefAs("id", "item_id"),
+		},
+		{
+			description: "dotted alias is quoted",
+			expected:    `u.url as "url.url"`,
+			expr:        RefAs("u.url", "url.url"),
+		},
+		{
+			description: "alias with quotes",
+			expected:    `title as "the ""title"""`,
+			expr:        As(Ref("title"), `the "title"`),
+		},
+		{
+			description: "leading digit is quoted",
+			expected:    `count(*) as "1st"`,
+			expr:        As(Func("count", Ref("*")), "1st"),
+		},
+		{
+			description: "uppercase alias is quoted to keep its case",
+			expected:    `name as "UserName"`,
+			expr:        As(Ref("name"), "UserName"),
+		},
+		{
+			description: "reserved in every dialect",
+			expected:    `created_at as "order"`,
+			expr:        RefAs("created_at", "order"),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			is.Equal(c.expected, c.expr.Build())
+			is.Equal(c.expected, c.expr.Build()) // building twice is stable
+			is.True(!strings.Contains(c.expr.Build(), "'"))
+		})
+	}
+}
+
+func TestAsDialects(t *testing.T) {
+	cases := []struct {
+		description string
+		expected    string
+		dialect     Dialect
+		expr        Expression
+	}{
+		{
+			description: "postgres reserved word",
+			expected:    `from users as "user"`,
+			dialect:     Postgres,
+			expr:        RefAs("users", "user"),
+		},
+		{
+			description: "sqlserver reserved word",
+			expected:    `from users as [user]`,
+			dialect:     SQLServer,
+			expr:        RefAs("users", "user"),
+		},
+		{
+			description: "not reserved in sqlite",
+			expected:    `from users as user`,
+			dialect:     SQLite,
+			expr:        RefAs("users", "user"),
+		},
+		{
+			description: "mysql reserved word",
+			expected:    "from ranges as `range`",
+			dialect:     MySQL,
+			expr:        RefAs("ranges", "range"),
+		},
+		{
+			description: "mysql uppercase",
+			expected:    "from users as `U`",
+			dialect:     MySQL,
+			expr:        RefAs("users", "U"),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			st := Select(Ref("*"), From(c.expr))
+			is.Equal("select * "+c.expected, st.BuildWithDialect(c.dialect))
+		})
+	}
+}
+
+func TestConst(t *testing.T) {
+	cases := []struct {
+		description string
+		expected    string
+		value       string
+	}{
+		{
+			description: "plain value",
+			expected:    "'earth'",
+			value:       "earth",
+		},
+		{
+			description: "empty string",
+			expected:    "''",
+			value:       "",
+		},
+		{
+			description: "single quote is doubled",
+			expected:    "'O''Brien'",
+			value:       "O'Brien",
+		},
+		{
+			description: "injection attempt stays inside the literal",
+			expected:    "'''; drop table items; --'",
+			value:       "'; drop table items; --",
+		},
+		{
+			description: "backslashes are left alone",
+			expected:    `'C:\temp\'`,
+			value:       `C:\temp\`,
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			is.Equal(c.expected, Const(c.value).Build())
+		})
+	}
+}
+
+func TestAndOr(t *testing.T) {
+	a := Equals(Ref("a"), Placeholder())
+	b := Equals(Ref("b"), Placeholder())
+	c := Equals(Ref("c"), Placeholder())
+
+	cases := []struct {
+		description string
+		expected    string
+		expr        Expression
+	}{
+		{
+			description: "or",
+			expected:    "(a = ? or b = ?)",
+			expr:        Or(a, b),
+		},
+		{
+			description: "and",
+			expected:    "(a = ? and b = ?)",
+			expr:        And(a, b),
+		},
+		{
+			description: "or nested in and",
+			expected:    "(a = ? and (b = ? or c = ?))",
+			expr:        And(a, Or(b, c)),
+		},
+		{
+			description: "deeply nested",
+			expected:    "((a = ? and (b = ? or (a = ? and c = ?))) or c = ?)",
+			expr:        Or(And(a, Or(b, And(a, c))), c),
+		},
+		{
+			description: "or in where",
+			expected:    "select * from items where ((a = ? or b = ?))",
+			expr:        Select(Ref("*"), From(Ref("items")), Where(Or(a, b))),
+		},
+		{
+			description: "or in where with other predicates",
+			expected:    "select * from items where (c = ? and (a = ? or b = ?))",
+			expr:        Select(Ref("*"), From(Ref("items")), Where(c, Or(a, b))),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			is.Equal(c.expected, c.expr.Build())
+		})
+	}
+}
+
+func TestNot(t *testing.T) {
+	a := Equals(Ref("a"), Placeholder())
+	b := Equals(Ref("b"), Placeholder())
+
+	cases := []struct {
+		description string
+		expected    string
+		expr        Expression
+	}{
+		{
+			description: "not in",
+			expected:    "not (id in (?))",
+			expr:        Not(In(Ref("id"), Placeholder())),
+		},
+		{
+			description: "not or",
+			expected:    "not ((a = ? or b = ?))",
+			expr:        Not(Or(a, b)),
+		},
+		{
+			description: "not in and",
+			expected:    "(not (a = ?) and b = ?)",
+			expr:        And(Not(a), b),
+		},
+		{
+			description: "not equals",
+			expected:    "a <> ?",
+			expr:        NotEquals(Ref("a"), Placeholder()),
+		},
+		{
+			description: "not in helper",
+			expected:    "id not in (?)",
+			expr:        NotIn(Ref("id"), Placeholder()),
+		},
+		{
+			description: "not between helper",
+			expected:    "n not between ?",
+			expr:        NotBetween(Ref("n"), Placeholder()),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			is.Equal(c.expected, c.expr.Build())
+		})
+	}
+}
+
+func TestBuildArgs(t *testing.T) {
+	cases := []struct {
+		description string
+		expected    string
+		args        []interface{}
+		statement   Statement
+	}{
+		{
+			description: "no args",
+			expected:    "select * from items",
+			statement:   Select(Ref("*"), From(Ref("items"))),
+		},
+		{
+			description: "where args",
+			expected:    "select * from items where (id = ? and title like ?)",
+			args:        []interface{}{1, "%foo%"},
+			statement: Select(
+				Ref("*"),
+				From(Ref("items")),
+				Where(
+					Equals(Ref("id"), Arg(1)),
+					Like(Ref("title"), Arg("%foo%")),
+				),
+			),
+		},
+		{
+			description: "args in subselect are in textual order",
+			expected:    "select * from (select id from items where (owner = ?)) join tags as t on t.name = ? where (id > ?)",
+			args:        []interface{}{"kyle", "go", 10},
+			statement: Select(
+				Ref("*"),
+				Where(Greater(Ref("id"), Arg(10))),
+				Join(RefAs("tags", "t"), Equals(Ref("t.name"), Arg("go"))),
+				FromSubselect(Select(
+					Ref("id"),
+					From(Ref("items")),
+					Where(Equals(Ref("owner"), Arg("kyle"))),
+				), ""),
+			),
+		},
+		{
+			description: "update args",
+			expected:    "update items set name = ?, count = count + 1 where (id = ?)",
+			args:        []interface{}{"new", 3},
+			statement: Update(
+				Ref("items"),
+				Where(Equals(Ref("id"), Arg(3))),
+				Set(
+					Equals(Ref("name"), Arg("new")),
+					Equals(Ref("count"), Ref("count + 1")),
+				),
+			),
+		},
+		{
+			description: "caller defined expression funcs",
+			expected:    "select * from items where (lower(name) = ? and id > ?)",
+			args:        []interface{}{"kyle", 3},
+			statement: Select(
+				Ref("*"),
+				From(Ref("items")),
+				Where(
+					Equals(ExpressionFunc(func() string { return "lower(name)" }), Arg("kyle")),
+					ContextExpressionFunc(func(ctx *BuildContext) string {
+						return "id > " + ctx.BindArg(3)
+					}),
+				),
+			),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			sql, args := c.statement.BuildArgs()
+			is.Equal(c.expected, sql)
+			is.Equal(c.args, args)
+		})
+	}
+}
+
+// benchmarkStatement is a statement shaped like the queries built in hot
+// paths: a windowed subselect with joins, grouping and placeholders.
+func benchmarkStatement() Statement {
 	st := Select(
 		Columns(
 			As(
@@ -94,7 +554,7 @@ func BenchmarkStatementBuilder(b *testing.B) {
 		),
 	)
 
-	st2 := Select(
+	return Select(
 		Columns(Ref("uu.*")),
 		FromSubselect(st, "uu"),
 		LeftJoin(RefAs("user_url_tags", "ut"), Equals(Ref("ut.user_url_id"), Ref("uu.id"))),
@@ -106,33 +566,103 @@ func BenchmarkStatementBuilder(b *testing.B) {
 		),
 		GroupBy("uu.id"),
 	)
+}
+
+func BenchmarkStatementBuilder(b *testing.B) {
+	st := benchmarkStatement()
+
+	b.ReportAllocs()
 
 	for i := 0; i < b.N; i++ {
-		st2.Build()
+		st.Build()
 	}
 }
 
-func ExampleSelect() {
-	st := Select(
-		Columns(
-			Ref("id"),
-			RefAs("generated_name", "name"),
-			As(Func("coalesce", Ref("location"), Const("earth")), "location"),
-		),
-		From(Ref("items")),
-		Where(Equals(Ref("id"), Placeholder())),
-		OrderBy("created_at"),
+func BenchmarkStatementBuilderParallel(b *testing.B) {
+	st := benchmarkStatement()
+
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			st.Build()
+		}
+	})
+}
+
+func TestConcurrentBuild(t *testing.T) {
+	is := is.New(t)
+	st := benchmarkStatement()
+	expected := st.Build()
+
+	var wg sync.WaitGroup
+	results := make(chan string, 64)
+
+	for i := 0; i < cap(results); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results <- st.Build()
+		}()
+	}
+
+	wg.Wait()
+	close(results)
+
+	for sql := range results {
+		is.Equal(expected, sql)
+	}
+}
+
+func ExampleSelect() {
+	st := Select(
+		Columns(
+			Ref("id"),
+			RefAs("generated_name", "name"),
+			As(Func("coalesce", Ref("location"), Const("earth")), "location"),
+		),
+		From(Ref("items")),
+		Where(Equals(Ref("id"), Placeholder())),
+		OrderBy("created_at"),
+	)
+
+	fmt.Println(st.Build())
+
+	// Output: select id, generated_name as name, coalesce(location, 'earth') as location from items where (id = ?) order by created_at
+}
+
+func ExampleUpdate() {
+	st := Update(
+		Ref("items"),
+		Set(Equals(Ref("name"), Placeholder())),
+		Where(Equals(Ref("id"), Placeholder())),
 	)
 
 	fmt.Println(st.Build())
 
-	// Output: select id, generated_name as 'name', coalesce(location, 'earth') as 'location' from items where (id = ?) order by created_at
+	// Output: update items set name = ? where (id = ?)
+}
+
+func ExampleStatement_BuildArgs() {
+	st := Select(
+		Ref("*"),
+		From(Ref("items")),
+		Where(Equals(Ref("id"), Arg(42)), Equals(Ref("owner"), Arg("kyle"))),
+	)
+
+	sql, args := st.BuildArgs()
+
+	fmt.Println(sql)
+	fmt.Println(args)
+
+	// Output:
+	// select * from items where (id = ? and owner = ?)
+	// [42 kyle]
 }
 
 func ExampleFrom() {
 	fmt.Println(Select(Ref("*"), From(RefAs("items", "i"))).Build())
 
-	// Output: select * from items as 'i'
+	// Output: select * from items as i
 }
 
 func ExampleFromSubselect() {
@@ -145,7 +675,7 @@ func ExampleFromSubselect() {
 
 	// Output:
 	// select * from (select 1 + 1)
-	// select * from (select 1 + 1) as 'result'
+	// select * from (select 1 + 1) as result
 }
 
 func ExampleWhere() {
@@ -157,3 +687,2708 @@ func ExampleWhere() {
 	// Output:
 	// select * from items where (foo = ? and bar = ?)
 }
+
+func ExampleLimit() {
+	st := Select(Ref("*"), From(Ref("items")), Limit(10), Offset(20))
+
+	fmt.Println(st.Build())
+
+	// Output: select * from items limit 10 offset 20
+}
+
+func TestCompound(t *testing.T) {
+	a := Select(Ref("id"), From(Ref("a")), Where(Equals(Ref("x"), Arg(1))))
+	b := Select(Ref("id"), From(Ref("b")), Where(Equals(Ref("x"), Arg(2))))
+	c := Select(Ref("id"), From(Ref("c")), Where(Equals(Ref("x"), Arg(3))))
+
+	cases := []struct {
+		description string
+		expected    string
+		args        []interface{}
+		statement   Statement
+	}{
+		{
+			description: "union",
+			expected:    "(select id from a where (x = ?)) union (select id from b where (x = ?))",
+			args:        []interface{}{1, 2},
+			statement:   Union(a, b),
+		},
+		{
+			description: "union all",
+			expected:    "(select id from a where (x = ?)) union all (select id from b where (x = ?))",
+			args:        []interface{}{1, 2},
+			statement:   UnionAll(a, b),
+		},
+		{
+			description: "intersect",
+			expected:    "(select id from a where (x = ?)) intersect (select id from b where (x = ?))",
+			args:        []interface{}{1, 2},
+			statement:   Intersect(a, b),
+		},
+		{
+			description: "except",
+			expected:    "(select id from b where (x = ?)) except (select id from a where (x = ?))",
+			args:        []interface{}{2, 1},
+			statement:   Except(b, a),
+		},
+		{
+			description: "chained unions flatten",
+			expected:    "(select id from a where (x = ?)) union (select id from b where (x = ?)) union (select id from c where (x = ?))",
+			args:        []interface{}{1, 2, 3},
+			statement:   Union(Union(a, b), c),
+		},
+		{
+			description: "mixed operators keep their grouping",
+			expected:    "((select id from a where (x = ?)) union (select id from b where (x = ?))) except (select id from c where (x = ?))",
+			args:        []interface{}{1, 2, 3},
+			statement:   Except(Union(a, b), c),
+		},
+		{
+			description: "order by and limit apply to the whole compound",
+			expected:    "(select id from a where (x = ?)) union (select id from b where (x = ?)) union (select id from c where (x = ?)) order by id limit 10",
+			args:        []interface{}{1, 2, 3},
+			statement:   Union(Union(a, b), c, OrderBy("id"), Limit(10)),
+		},
+		{
+			description: "compound with clauses is not flattened",
+			expected:    "((select id from a where (x = ?)) union (select id from b where (x = ?)) limit 1) union (select id from c where (x = ?))",
+			args:        []interface{}{1, 2, 3},
+			statement:   Union(Union(a, b, Limit(1)), c),
+		},
+		{
+			description: "compound with common table expressions is not flattened",
+			expected:    "(with t as (select id from d) (select id from a where (x = ?)) union (select id from t)) union (select id from c where (x = ?))",
+			args:        []interface{}{1, 3},
+			statement:   Union(Union(a, Select(Ref("id"), From(Ref("t"))), With("t", Select(Ref("id"), From(Ref("d"))))), c),
+		},
+		{
+			description: "compound with its own placeholder is not flattened",
+			expected:    "((select id from a where (x = $1)) union (select id from b where (x = $2))) union (select id from c where (x = ?))",
+			args:        []interface{}{1, 2, 3},
+			statement:   Union(Union(a, b, WithPlaceholder("$%d")), c),
+		},
+		{
+			description: "compound as a subselect",
+			expected:    "select count(*) from ((select id from a where (x = ?)) union (select id from b where (x = ?))) as ids",
+			args:        []interface{}{1, 2},
+			statement:   Select(Func("count", Ref("*")), FromSubselect(Union(a, b), "ids")),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			sql, args := c.statement.BuildArgs()
+			is.Equal(c.expected, sql)
+			is.Equal(c.args, args)
+		})
+	}
+}
+
+func TestWith(t *testing.T) {
+	inner := Select(Ref("id"), From(Ref("items")), Where(Equals(Ref("owner"), Arg("kyle"))))
+
+	cases := []struct {
+		description string
+		expected    string
+		args        []interface{}
+		statement   Statement
+	}{
+		{
+			description: "single cte",
+			expected:    "with t as (select id from items where (owner = ?)) select * from t where (id > ?)",
+			args:        []interface{}{"kyle", 10},
+			statement: Select(
+				Ref("*"),
+				From(Ref("t")),
+				Where(Greater(Ref("id"), Arg(10))),
+				With("t", inner),
+			),
+		},
+		{
+			description: "multiple ctes share one with",
+			expected:    "with t as (select id from items where (owner = ?)), u as (select id from t) select * from u",
+			args:        []interface{}{"kyle"},
+			statement: Select(
+				Ref("*"),
+				From(Ref("u")),
+				With("t", inner),
+				With("u", Select(Ref("id"), From(Ref("t")))),
+			),
+		},
+		{
+			description: "recursive",
+			expected:    "with recursive tree as ((select id, parent_id from nodes where (id = ?)) union all (select n.id, n.parent_id from nodes as n join tree on n.parent_id = tree.id)) select * from tree",
+			args:        []interface{}{1},
+			statement: Select(
+				Ref("*"),
+				From(Ref("tree")),
+				WithRecursive("tree", UnionAll(
+					Select(Ref("id, parent_id"), From(Ref("nodes")), Where(Equals(Ref("id"), Arg(1)))),
+					Select(
+						Ref("n.id, n.parent_id"),
+						From(RefAs("nodes", "n")),
+						Join(Ref("tree"), Equals(Ref("n.parent_id"), Ref("tree.id"))),
+					),
+				)),
+			),
+		},
+		{
+			description: "column names",
+			expected:    "with t(a, b) as (select id, owner from items where (owner = ?)) select a from t",
+			args:        []interface{}{"kyle"},
+			statement: Select(
+				Ref("a"),
+				From(Ref("t")),
+				With("t", Select(Columns(Ref("id"), Ref("owner")), From(Ref("items")), Where(Equals(Ref("owner"), Arg("kyle")))), WithColumns("a", "b")),
+			),
+		},
+		{
+			description: "recursive with column names",
+			expected:    "with recursive nums(n) as ((select 1) union all (select n + 1 from nums where (n < ?))) select n from nums",
+			args:        []interface{}{10},
+			statement: Select(
+				Ref("n"),
+				From(Ref("nums")),
+				WithRecursive("nums", UnionAll(
+					Select(Ref("1")),
+					Select(Ref("n + 1"), From(Ref("nums")), Where(Less(Ref("n"), Arg(10)))),
+				), WithColumns("n")),
+			),
+		},
+		{
+			description: "cte on update",
+			expected:    "with t as (select id from items where (owner = ?)) update items set seen = ? where (id in (select id from t))",
+			args:        []interface{}{"kyle", true},
+			statement: Update(
+				Ref("items"),
+				With("t", inner),
+				Set(Equals(Ref("seen"), Arg(true))),
+				Where(In(Ref("id"), Select(Ref("id"), From(Ref("t"))))),
+			),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			sql, args := c.statement.BuildArgs()
+			is.Equal(c.expected, sql)
+			is.Equal(c.args, args)
+		})
+	}
+}
+
+func TestWithPostgresPlaceholders(t *testing.T) {
+	is := is.New(t)
+
+	st := Select(
+		Ref("*"),
+		From(Ref("t")),
+		Where(Greater(Ref("id"), Placeholder())),
+		With("t", Select(Ref("id"), From(Ref("items")), Where(Equals(Ref("owner"), Placeholder())))),
+	)
+
+	is.Equal("with t as (select id from items where (owner = $1)) select * from t where (id > $2)", st.BuildWithDialect(Postgres))
+}
+
+func TestJoins(t *testing.T) {
+	cases := []struct {
+		description string
+		expected    string
+		statement   Statement
+	}{
+		{
+			description: "inner join",
+			expected:    "select * from items as i inner join users as u on u.id = i.user_id",
+			statement: Select(
+				Ref("*"),
+				From(RefAs("items", "i")),
+				InnerJoin(RefAs("users", "u"), Equals(Ref("u.id"), Ref("i.user_id"))),
+			),
+		},
+		{
+			description: "right and full joins",
+			expected:    "select * from a right join b on b.a_id = a.id full join c on c.b_id = b.id and c.kind = ?",
+			statement: Select(
+				Ref("*"),
+				From(Ref("a")),
+				RightJoin(Ref("b"), Equals(Ref("b.a_id"), Ref("a.id"))),
+				FullJoin(Ref("c"), Equals(Ref("c.b_id"), Ref("b.id")), Equals(Ref("c.kind"), Placeholder())),
+			),
+		},
+		{
+			description: "cross join has no on",
+			expected:    "select * from a cross join b",
+			statement:   Select(Ref("*"), From(Ref("a")), CrossJoin(Ref("b"))),
+		},
+		{
+			description: "join using",
+			expected:    "select * from orders join customers using (customer_id, region)",
+			statement: Select(
+				Ref("*"),
+				From(Ref("orders")),
+				JoinUsing(Ref("customers"), "customer_id", "region"),
+			),
+		},
+		{
+			description: "join using with join and left join",
+			expected:    "select * from orders as o join customers using (customer_id) left join notes as n on n.order_id = o.id join items as i on i.order_id = o.id",
+			statement: Select(
+				Ref("*"),
+				From(RefAs("orders", "o")),
+				JoinUsing(Ref("customers"), "customer_id"),
+				LeftJoin(RefAs("notes", "n"), Equals(Ref("n.order_id"), Ref("o.id"))),
+				Join(RefAs("items", "i"), Equals(Ref("i.order_id"), Ref("o.id"))),
+			),
+		},
+		{
+			description: "mixed joins keep insertion order",
+			expected:    "select * from a left join b on b.a_id = a.id join c on c.b_id = b.id cross join d inner join e on e.id = d.e_id where (a.id = ?)",
+			statement: Select(
+				Ref("*"),
+				From(Ref("a")),
+				Where(Equals(Ref("a.id"), Placeholder())),
+				LeftJoin(Ref("b"), Equals(Ref("b.a_id"), Ref("a.id"))),
+				Join(Ref("c"), Equals(Ref("c.b_id"), Ref("b.id"))),
+				CrossJoin(Ref("d")),
+				InnerJoin(Ref("e"), Equals(Ref("e.id"), Ref("d.e_id"))),
+			),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			is.Equal(c.expected, c.statement.Build())
+		})
+	}
+}
+
+func TestOrderByExpr(t *testing.T) {
+	cases := []struct {
+		description string
+		expected    string
+		statement   Statement
+	}{
+		{
+			description: "sort directions",
+			expected:    "select * from items order by created_at desc, id asc",
+			statement: Select(
+				Ref("*"),
+				From(Ref("items")),
+				OrderByExpr(Desc(Ref("created_at")), Asc(Ref("id"))),
+			),
+		},
+		{
+			description: "nulls ordering",
+			expected:    "select * from items order by published_at desc nulls last, rank nulls first",
+			statement: Select(
+				Ref("*"),
+				From(Ref("items")),
+				OrderByExpr(NullsLast(Desc(Ref("published_at"))), NullsFirst(Ref("rank"))),
+			),
+		},
+		{
+			description: "function expression",
+			expected:    "select * from items order by lower(title) asc",
+			statement: Select(
+				Ref("*"),
+				From(Ref("items")),
+				OrderByExpr(Asc(Func("lower", Ref("title")))),
+			),
+		},
+		{
+			description: "string order by is unchanged",
+			expected:    "select * from items order by created_at, id",
+			statement:   Select(Ref("*"), From(Ref("items")), OrderBy("created_at", "id")),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			is.Equal(c.expected, c.statement.Build())
+		})
+	}
+}
+
+func TestCast(t *testing.T) {
+	cases := []struct {
+		description string
+		expected    string
+		expr        Expression
+	}{
+		{
+			description: "cast column",
+			expected:    "cast(amount as numeric(10,2))",
+			expr:        Cast(Ref("amount"), "numeric(10,2)"),
+		},
+		{
+			description: "cast function result",
+			expected:    "cast(sum(x) as bigint) as total",
+			expr:        As(Cast(Func("sum", Ref("x")), "bigint"), "total"),
+		},
+		{
+			description: "cast operator",
+			expected:    "created_at::date",
+			expr:        CastOp(Ref("created_at"), "date"),
+		},
+		{
+			description: "casts in columns",
+			expected:    "cast(? as int), ?::text as label",
+			expr:        Columns(Cast(Placeholder(), "int"), As(CastOp(Placeholder(), "text"), "label")),
+		},
+		{
+			description: "double cast",
+			expected:    "x::text::int",
+			expr:        CastOp(CastOp(Ref("x"), "text"), "int"),
+		},
+		{
+			description: "json text cast",
+			expected:    "(data ->> 'age')::int",
+			expr:        CastOp(JSONGetText(Ref("data"), Const("age")), "int"),
+		},
+		{
+			description: "chained json text cast",
+			expected:    "(data ->> 'age')::text::int",
+			expr:        CastOp(CastOp(JSONGetText(Ref("data"), Const("age")), "text"), "int"),
+		},
+		{
+			description: "function and literal operands",
+			expected:    "sum(x)::bigint, 'a b'::text, (a + b)::int",
+			expr: Columns(
+				CastOp(Func("sum", Ref("x")), "bigint"),
+				CastOp(Const("a b"), "text"),
+				CastOp(Add(Ref("a"), Ref("b")), "int"),
+			),
+		},
+		{
+			description: "collated operand",
+			expected:    `(name collate "C")::text`,
+			expr:        CastOp(Collate(Ref("name"), "C"), "text"),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			is.Equal(c.expected, c.expr.Build())
+		})
+	}
+}
+
+func TestExists(t *testing.T) {
+	sub := Select(Ref("1"), From(Ref("t")), Where(Equals(Ref("t.id"), Ref("u.id"))))
+
+	cases := []struct {
+		description string
+		expected    string
+		statement   Statement
+	}{
+		{
+			description: "exists",
+			expected:    "select * from u where (exists (select 1 from t where (t.id = u.id)))",
+			statement:   Select(Ref("*"), From(Ref("u")), Where(Exists(sub))),
+		},
+		{
+			description: "not exists",
+			expected:    "select * from u where (u.active = ? and not exists (select 1 from t where (t.id = u.id)))",
+			statement:   Select(Ref("*"), From(Ref("u")), Where(Equals(Ref("u.active"), Placeholder()), NotExists(sub))),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			is.Equal(c.expected, c.statement.Build())
+		})
+	}
+}
+
+func TestInList(t *testing.T) {
+	cases := []struct {
+		description string
+		expected    string
+		expr        Expression
+	}{
+		{
+			description: "placeholders",
+			expected:    "id in (?, ?, ?)",
+			expr:        InList(Ref("id"), 3),
+		},
+		{
+			description: "single placeholder",
+			expected:    "id in (?)",
+			expr:        InList(Ref("id"), 1),
+		},
+		{
+			description: "no placeholders",
+			expected:    "id in (null)",
+			expr:        InList(Ref("id"), 0),
+		},
+		{
+			description: "values",
+			expected:    "kind in ('a', 'b')",
+			expr:        InValues(Ref("kind"), Const("a"), Const("b")),
+		},
+		{
+			description: "no values",
+			expected:    "kind in (null)",
+			expr:        InValues(Ref("kind")),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			is.Equal(c.expected, c.expr.Build())
+		})
+	}
+}
+
+func TestInListPostgres(t *testing.T) {
+	is := is.New(t)
+
+	st := Select(Ref("*"), From(Ref("items")), Where(InList(Ref("id"), 3), Equals(Ref("owner"), Placeholder())))
+
+	is.Equal("select * from items where (id in ($1, $2, $3) and owner = $4)", st.BuildWithDialect(Postgres))
+}
+
+func TestArithmetic(t *testing.T) {
+	cases := []struct {
+		description string
+		expected    string
+		expr        Expression
+	}{
+		{
+			description: "operators",
+			expected:    "(a + b), (a - b), (a * b), (a / b), (a % b)",
+			expr: Columns(
+				Add(Ref("a"), Ref("b")),
+				Sub(Ref("a"), Ref("b")),
+				Mul(Ref("a"), Ref("b")),
+				Div(Ref("a"), Ref("b")),
+				Mod(Ref("a"), Ref("b")),
+			),
+		},
+		{
+			description: "aliased",
+			expected:    "(price * qty) as total",
+			expr:        As(Mul(Ref("price"), Ref("qty")), "total"),
+		},
+		{
+			description: "nested",
+			expected:    "((a * b) + c)",
+			expr:        Add(Mul(Ref("a"), Ref("b")), Ref("c")),
+		},
+		{
+			description: "nested on the right",
+			expected:    "(a * (b + ?))",
+			expr:        Mul(Ref("a"), Add(Ref("b"), Placeholder())),
+		},
+		{
+			description: "in a comparison",
+			expected:    "(stock - reserved) > ?",
+			expr:        Greater(Sub(Ref("stock"), Ref("reserved")), Placeholder()),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			is.Equal(c.expected, c.expr.Build())
+		})
+	}
+}
+
+func TestInsert(t *testing.T) {
+	cases := []struct {
+		description string
+		expected    string
+		statement   Statement
+	}{
+		{
+			description: "simple insert",
+			expected:    "insert into items (name, title) values (?, ?)",
+			statement: Insert(
+				Ref("items"),
+				InsertColumns("name", "title"),
+				Values(Placeholder(), Placeholder()),
+			),
+		},
+		{
+			description: "multiple rows",
+			expected:    "insert into items (name) values (?), (?)",
+			statement: Insert(
+				Ref("items"),
+				InsertColumns("name"),
+				Values(Placeholder()),
+				Values(Placeholder()),
+			),
+		},
+		{
+			description: "returning",
+			expected:    "insert into items (name) values (?) returning id",
+			statement: Insert(
+				Ref("items"),
+				Returning(Ref("id")),
+				InsertColumns("name"),
+				Values(Placeholder()),
+			),
+		},
+		{
+			description: "returning star",
+			expected:    "insert into items (name) values (?) returning *",
+			statement: Insert(
+				Ref("items"),
+				InsertColumns("name"),
+				Values(Placeholder()),
+				Returning(Ref("*")),
+			),
+		},
+		{
+			description: "multiple returning merge",
+			expected:    "insert into items (name) values (?) returning id, created_at as created",
+			statement: Insert(
+				Ref("items"),
+				InsertColumns("name"),
+				Values(Placeholder()),
+				Returning(Ref("id")),
+				Returning(RefAs("created_at", "created")),
+			),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			is.Equal(c.expected, c.statement.Build())
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	cases := []struct {
+		description string
+		expected    string
+		statement   Statement
+	}{
+		{
+			description: "simple delete",
+			expected:    "delete from items where (id = ?)",
+			statement:   Delete(Ref("items"), Where(Equals(Ref("id"), Placeholder()))),
+		},
+		{
+			description: "delete everything",
+			expected:    "delete from items",
+			statement:   Delete(Ref("items")),
+		},
+		{
+			description: "returning",
+			expected:    "delete from items where (id = ?) returning id, name",
+			statement: Delete(
+				Ref("items"),
+				Returning(Ref("id"), Ref("name")),
+				Where(Equals(Ref("id"), Placeholder())),
+			),
+		},
+		{
+			description: "update returning",
+			expected:    "update items set name = ? where (id = ?) returning updated_at",
+			statement: Update(
+				Ref("items"),
+				Set(Equals(Ref("name"), Placeholder())),
+				Where(Equals(Ref("id"), Placeholder())),
+				Returning(Ref("updated_at")),
+			),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			is.Equal(c.expected, c.statement.Build())
+		})
+	}
+}
+
+func TestSelectReturning(t *testing.T) {
+	is := is.New(t)
+
+	st := Select(Ref("*"), From(Ref("items")), Returning(Ref("id")))
+
+	is.True(errors.Is(st.Err(), ErrInvalidClause))
+	is.Equal("", st.Build())
+}
+
+func TestOnConflict(t *testing.T) {
+	cases := []struct {
+		description string
+		expected    string
+		statement   Statement
+	}{
+		{
+			description: "do update",
+			expected:    "insert into items (id, name) values (?, ?) on conflict (id) do update set name = ?",
+			statement: Insert(
+				Ref("items"),
+				InsertColumns("id", "name"),
+				Values(Placeholder(), Placeholder()),
+				OnConflict("id"),
+				DoUpdate(Equals(Ref("name"), Placeholder())),
+			),
+		},
+		{
+			description: "do update with excluded",
+			expected:    "insert into items (id, name) values (?, ?) on conflict (id, kind) do update set name = excluded.name, updated_at = now() returning id",
+			statement: Insert(
+				Ref("items"),
+				InsertColumns("id", "name"),
+				Values(Placeholder(), Placeholder()),
+				Returning(Ref("id")),
+				DoUpdate(Equals(Ref("name"), Ref("excluded.name"))),
+				OnConflict("id", "kind"),
+				DoUpdate(Equals(Ref("updated_at"), Func("now"))),
+			),
+		},
+		{
+			description: "do nothing",
+			expected:    "insert into items (id) values (?) on conflict (id) do nothing",
+			statement: Insert(
+				Ref("items"),
+				InsertColumns("id"),
+				Values(Placeholder()),
+				OnConflict("id"),
+				DoNothing(),
+			),
+		},
+		{
+			description: "do nothing without a target",
+			expected:    "insert into items (id) values (?) on conflict do nothing",
+			statement: Insert(
+				Ref("items"),
+				InsertColumns("id"),
+				Values(Placeholder()),
+				DoNothing(),
+			),
+		},
+		{
+			description: "excluded helper",
+			expected:    "insert into items (id, name) values (?, ?) on conflict (id) do update set name = excluded.name",
+			statement: Insert(
+				Ref("items"),
+				InsertColumns("id", "name"),
+				Values(Placeholder(), Placeholder()),
+				OnConflict("id"),
+				DoUpdate(Equals(Ref("name"), Excluded("name"))),
+			),
+		},
+		{
+			description: "do update all",
+			expected:    "insert into items (id, name, kind) values (?, ?, ?) on conflict (id) do update set name = excluded.name, kind = excluded.kind, updated_at = now()",
+			statement: Insert(
+				Ref("items"),
+				InsertColumns("id", "name", "kind"),
+				Values(Placeholder(), Placeholder(), Placeholder()),
+				OnConflict("id"),
+				DoUpdateAll("name", "kind"),
+				DoUpdate(Equals(Ref("updated_at"), Func("now"))),
+			),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			is.Equal(c.expected, c.statement.Build())
+		})
+	}
+}
+
+func TestDoUpdateAllMySQL(t *testing.T) {
+	is := is.New(t)
+
+	st := Insert(Ref("items"), InsertColumns("id", "name", "kind"), Values(Placeholder(), Placeholder(), Placeholder()),
+		OnConflict("id"), DoUpdateAll("name", "kind"))
+
+	is.Equal("insert into items (id, name, kind) values (?, ?, ?) on duplicate key update name = values(name), kind = values(kind)", st.BuildWithDialect(MySQL))
+}
+
+func TestBetweenAnd(t *testing.T) {
+	cases := []struct {
+		description string
+		expected    string
+		statement   Statement
+	}{
+		{
+			description: "between",
+			expected:    "select * from users where (age between ? and ?)",
+			statement:   Select(Ref("*"), From(Ref("users")), Where(BetweenAnd(Ref("age"), Placeholder(), Placeholder()))),
+		},
+		{
+			description: "not between",
+			expected:    "select * from users where (age not between 18 and 65 and active = ?)",
+			statement: Select(Ref("*"), From(Ref("users")), Where(
+				NotBetweenAnd(Ref("age"), Ref("18"), Ref("65")),
+				Equals(Ref("active"), Placeholder()),
+			)),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			is.Equal(c.expected, c.statement.Build())
+		})
+	}
+}
+
+func TestWindow(t *testing.T) {
+	cases := []struct {
+		description string
+		expected    string
+		expr        Expression
+	}{
+		{
+			description: "empty window",
+			expected:    "count(*) over ()",
+			expr:        Window("count(*)"),
+		},
+		{
+			description: "order by",
+			expected:    "row_number() over (order by id)",
+			expr:        Window("row_number()", OrderByC("id")),
+		},
+		{
+			description: "partition by",
+			expected:    "sum(total) over (partition by a, b)",
+			expr:        Window("sum(total)", PartitionBy("a", "b")),
+		},
+		{
+			description: "partition and order by",
+			expected:    "row_number() over (partition by a order by b)",
+			expr:        Window("row_number()", PartitionBy("a"), OrderByC("b")),
+		},
+		{
+			description: "running total",
+			expected:    "sum(total) over (order by day rows between unbounded preceding and current row)",
+			expr:        Window("sum(total)", OrderByC("day"), Frame("rows", "unbounded preceding", "current row")),
+		},
+		{
+			description: "moving average",
+			expected:    "avg(total) over (partition by shop order by day rows between 6 preceding and current row)",
+			expr:        Window("avg(total)", PartitionBy("shop"), OrderByC("day"), Frame("rows", "6 preceding", "current row")),
+		},
+		{
+			description: "frame without end",
+			expected:    "sum(total) over (order by day range unbounded preceding)",
+			expr:        Window("sum(total)", OrderByC("day"), Frame("range", "unbounded preceding", "")),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			is.Equal(c.expected, c.expr.Build())
+		})
+	}
+}
+
+func TestRowIn(t *testing.T) {
+	cols := []Expression{Ref("a"), Ref("b")}
+
+	cases := []struct {
+		description string
+		expected    string
+		expr        Expression
+	}{
+		{
+			description: "placeholders",
+			expected:    "(a, b) in ((?, ?), (?, ?))",
+			expr:        RowIn(cols, 2),
+		},
+		{
+			description: "single row",
+			expected:    "(a, b) in ((?, ?))",
+			expr:        RowIn(cols, 1),
+		},
+		{
+			description: "no rows",
+			expected:    "(a, b) in ((null, null))",
+			expr:        RowIn(cols, 0),
+		},
+		{
+			description: "values",
+			expected:    "(a, b) in ((1, 'x'), (2, 'y'))",
+			expr: RowInValues(cols,
+				[]Expression{Ref("1"), Const("x")},
+				[]Expression{Ref("2"), Const("y")},
+			),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			is.Equal(c.expected, c.expr.Build())
+		})
+	}
+}
+
+func TestRowInPostgres(t *testing.T) {
+	is := is.New(t)
+
+	st := Select(Ref("*"), From(Ref("t")), Where(RowIn([]Expression{Ref("a"), Ref("b")}, 2)))
+
+	is.Equal("select * from t where ((a, b) in (($1, $2), ($3, $4)))", st.BuildWithDialect(Postgres))
+}
+
+func TestRowInValuesMismatch(t *testing.T) {
+	is := is.New(t)
+
+	defer func() {
+		is.True(recover() != nil)
+	}()
+
+	RowInValues([]Expression{Ref("a"), Ref("b")}, []Expression{Ref("1")})
+}
+
+func TestLocking(t *testing.T) {
+	cases := []struct {
+		description string
+		expected    string
+		statement   Statement
+	}{
+		{
+			description: "for update",
+			expected:    "select * from jobs where (state = ?) for update",
+			statement:   Select(Ref("*"), From(Ref("jobs")), Where(Equals(Ref("state"), Placeholder())), ForUpdate()),
+		},
+		{
+			description: "for share",
+			expected:    "select * from jobs for share",
+			statement:   Select(Ref("*"), From(Ref("jobs")), ForShare()),
+		},
+		{
+			description: "after order by and limit",
+			expected:    "select * from jobs order by created_at limit 10 for update skip locked",
+			statement:   Select(Ref("*"), From(Ref("jobs")), ForUpdate(), SkipLocked(), OrderBy("created_at"), Limit(10)),
+		},
+		{
+			description: "of tables",
+			expected:    "select * from jobs as j join queues as q on q.id = j.queue_id for update of j, q nowait",
+			statement: Select(Ref("*"), From(RefAs("jobs", "j")),
+				Join(RefAs("queues", "q"), Equals(Ref("q.id"), Ref("j.queue_id"))),
+				ForUpdateOf("j", "q"), NoWait(),
+			),
+		},
+		{
+			description: "skip locked implies for update",
+			expected:    "select * from t for update skip locked",
+			statement:   Select(Ref("*"), From(Ref("t")), SkipLocked()),
+		},
+		{
+			description: "share skip locked",
+			expected:    "select * from t for share skip locked",
+			statement:   Select(Ref("*"), From(Ref("t")), SkipLocked(), ForShare()),
+		},
+		{
+			description: "with group by",
+			expected:    "select kind from t group by kind for update",
+			statement:   Select(Ref("kind"), From(Ref("t")), ForUpdate(), GroupBy("kind")),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			is.Equal(c.expected, c.statement.Build())
+		})
+	}
+}
+
+func TestScalarSubquery(t *testing.T) {
+	orders := Select(CountAll(), From(RefAs("orders", "o")), Where(Equals(Ref("o.user_id"), Ref("u.id"))))
+
+	cases := []struct {
+		description string
+		expected    string
+		args        []interface{}
+		statement   Statement
+	}{
+		{
+			description: "column",
+			expected:    "select u.id, (select count(*) from orders as o where (o.user_id = u.id)) as order_count from users as u",
+			statement: Select(
+				Columns(Ref("u.id"), As(ScalarSubquery(orders), "order_count")),
+				From(RefAs("users", "u")),
+			),
+		},
+		{
+			description: "comparison",
+			expected:    "select * from users where (id = ? and score > (select avg(score) from users where (team = ?)))",
+			args:        []interface{}{1, "a"},
+			statement: Select(Ref("*"), From(Ref("users")), Where(
+				Equals(Ref("id"), Arg(1)),
+				Greater(Ref("score"), ScalarSubquery(Select(Avg(Ref("score")), From(Ref("users")), Where(Equals(Ref("team"), Arg("a")))))),
+			)),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			sql, args := c.statement.BuildArgs()
+			is.Equal(c.expected, sql)
+			is.Equal(c.args, args)
+		})
+	}
+}
+
+func TestAnyAll(t *testing.T) {
+	sub := Select(Ref("price"), From(Ref("items")), Where(Equals(Ref("kind"), Placeholder())))
+
+	cases := []struct {
+		description string
+		expected    string
+		dialect     Dialect
+		statement   Statement
+	}{
+		{
+			description: "any",
+			expected:    "select * from offers where (price > any (select price from items where (kind = ?)))",
+			statement:   Select(Ref("*"), From(Ref("offers")), Where(Any(">", Ref("price"), sub))),
+		},
+		{
+			description: "all",
+			expected:    "select * from offers where (price <= all (select price from items where (kind = ?)))",
+			statement:   Select(Ref("*"), From(Ref("offers")), Where(All("<=", Ref("price"), sub))),
+		},
+		{
+			description: "numbered placeholders",
+			expected:    "select * from offers where (seller = $1 and price = any (select price from items where (kind = $2)) and active = $3)",
+			dialect:     Postgres,
+			statement: Select(Ref("*"), From(Ref("offers")), Where(
+				Equals(Ref("seller"), Placeholder()),
+				Any("=", Ref("price"), sub),
+				Equals(Ref("active"), Placeholder()),
+			)),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			is.Equal(c.expected, c.statement.BuildWithDialect(c.dialect))
+		})
+	}
+}
+
+func TestMultipleWhere(t *testing.T) {
+	cases := []struct {
+		description string
+		expected    string
+		args        []interface{}
+		statement   Statement
+	}{
+		{
+			description: "two groups",
+			expected:    "select * from t where (a = ? and b = ?) and (c = ?)",
+			args:        []interface{}{1, 2, 3},
+			statement: Select(Ref("*"), From(Ref("t")),
+				Where(Equals(Ref("a"), Arg(1)), Equals(Ref("b"), Arg(2))),
+				Where(Equals(Ref("c"), Arg(3))),
+			),
+		},
+		{
+			description: "three groups",
+			expected:    "select * from t where (a = ?) and (b = ? and c = ?) and (d = ?)",
+			args:        []interface{}{1, 2, 3, 4},
+			statement: Select(Ref("*"), From(Ref("t")),
+				Where(Equals(Ref("a"), Arg(1))),
+				Where(Equals(Ref("b"), Arg(2)), Equals(Ref("c"), Arg(3))),
+				Where(Equals(Ref("d"), Arg(4))),
+			),
+		},
+		{
+			description: "groups split by other clauses",
+			expected:    "select * from t where (a = ?) and (b = ?) order by a limit 1",
+			args:        []interface{}{1, 2},
+			statement: Select(Ref("*"), From(Ref("t")),
+				Where(Equals(Ref("a"), Arg(1))),
+				OrderBy("a"),
+				Limit(1),
+				Where(Equals(Ref("b"), Arg(2))),
+			),
+		},
+		{
+			description: "update",
+			expected:    "update t set a = ? where (b = ?) and (c = ?) and (d = ?)",
+			args:        []interface{}{1, 2, 3, 4},
+			statement: Update(Ref("t"),
+				Set(Equals(Ref("a"), Arg(1))),
+				Where(Equals(Ref("b"), Arg(2))),
+				Where(Equals(Ref("c"), Arg(3))),
+				Where(Equals(Ref("d"), Arg(4))),
+			),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			sql, args := c.statement.BuildArgs()
+			is.Equal(c.expected, sql)
+			is.Equal(c.args, args)
+			is.Equal(1, strings.Count(sql, "where"))
+		})
+	}
+}
+
+func TestGroup(t *testing.T) {
+	a := Equals(Ref("a"), Placeholder())
+	b := Equals(Ref("b"), Placeholder())
+	c := Equals(Ref("c"), Placeholder())
+	d := Equals(Ref("d"), Placeholder())
+
+	cases := []struct {
+		description string
+		expected    string
+		expr        Expression
+	}{
+		{
+			description: "group",
+			expected:    "(a = ? and b = ?)",
+			expr:        Group(a, b),
+		},
+		{
+			description: "group in or",
+			expected:    "select * from t where (a = ? and ((b = ? and c = ?) or d = ?))",
+			expr:        Select(Ref("*"), From(Ref("t")), Where(a, Or(Group(b, c), d))),
+		},
+		{
+			description: "deeply nested",
+			expected:    "select * from t where ((((a = ? or b = ?) and c = ?) or d = ?))",
+			expr:        Select(Ref("*"), From(Ref("t")), Where(Or(Group(Or(a, b), c), d))),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			sql := c.expr.Build()
+			is.Equal(c.expected, sql)
+			is.Equal(strings.Count(sql, "("), strings.Count(sql, ")"))
+		})
+	}
+}
+
+func TestOrderByCollate(t *testing.T) {
+	cases := []struct {
+		description string
+		expected    string
+		dialect     Dialect
+		statement   Statement
+	}{
+		{
+			description: "collation",
+			expected:    `select * from users order by name collate "C"`,
+			statement:   Select(Ref("*"), From(Ref("users")), OrderByExpr(OrderByCollate("name", "C", ""))),
+		},
+		{
+			description: "direction and nulls",
+			expected:    `select * from users order by name collate "C" asc nulls last, id desc`,
+			statement: Select(Ref("*"), From(Ref("users")), OrderByExpr(
+				NullsLast(OrderByCollate("name", "C", "asc")),
+				Desc(Ref("id")),
+			)),
+		},
+		{
+			description: "dialect quoting",
+			expected:    "select * from users order by name collate `utf8mb4_bin` desc",
+			dialect:     MySQL,
+			statement:   Select(Ref("*"), From(Ref("users")), OrderByExpr(OrderByCollate("name", "utf8mb4_bin", "desc"))),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			is.Equal(c.expected, c.statement.BuildWithDialect(c.dialect))
+		})
+	}
+}
+
+func TestUpdateFromAndUsing(t *testing.T) {
+	cases := []struct {
+		description string
+		expected    string
+		args        []interface{}
+		statement   Statement
+	}{
+		{
+			description: "update from",
+			expected:    "update accounts as a set balance = a.balance + p.amount from payments as p where (p.account_id = a.id and p.state = $1)",
+			args:        []interface{}{"pending"},
+			statement: Update(RefAs("accounts", "a"),
+				Set(Equals(Ref("balance"), Ref("a.balance + p.amount"))),
+				UpdateFrom(RefAs("payments", "p")),
+				Where(Equals(Ref("p.account_id"), Ref("a.id")), Equals(Ref("p.state"), Arg("pending"))),
+			),
+		},
+		{
+			description: "placeholder order across set, from and where",
+			expected:    "update t set a = $1 from (select id from u where (kind = $2)) as s where (t.id = s.id and t.b = $3) returning t.id",
+			args:        []interface{}{1, "x", 2},
+			statement: Update(Ref("t"),
+				Where(Equals(Ref("t.id"), Ref("s.id")), Equals(Ref("t.b"), Arg(2))),
+				Returning(Ref("t.id")),
+				UpdateFrom(As(Wrap(Select(Ref("id"), From(Ref("u")), Where(Equals(Ref("kind"), Arg("x"))))), "s")),
+				Set(Equals(Ref("a"), Arg(1))),
+			),
+		},
+		{
+			description: "delete using",
+			expected:    "delete from sessions as s using users as u, teams as t where (s.user_id = u.id and u.team_id = t.id and t.name = $1)",
+			args:        []interface{}{"old"},
+			statement: Delete(RefAs("sessions", "s"),
+				Using(RefAs("users", "u")),
+				Where(Equals(Ref("s.user_id"), Ref("u.id")), Equals(Ref("u.team_id"), Ref("t.id")), Equals(Ref("t.name"), Arg("old"))),
+				Using(RefAs("teams", "t")),
+			),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			sql, args := c.statement.BuildArgsWithDialect(Postgres)
+			is.Equal(c.expected, sql)
+			is.Equal(c.args, args)
+			is.NoErr(c.statement.Validate())
+		})
+	}
+}
+
+func TestFromFunc(t *testing.T) {
+	cases := []struct {
+		description string
+		expected    string
+		dialect     Dialect
+		statement   Statement
+	}{
+		{
+			description: "unnest with columns",
+			expected:    "select id from unnest($1) as t(id)",
+			dialect:     Postgres,
+			statement:   Select(Ref("id"), FromFunc(Func("unnest", Placeholder()), "t", "id")),
+		},
+		{
+			description: "without columns",
+			expected:    "select * from generate_series(1, 10) as g",
+			statement:   Select(Ref("*"), FromFunc(Func("generate_series", Ref("1"), Ref("10")), "g")),
+		},
+		{
+			description: "with other sources",
+			expected:    "select * from items as i, unnest(?, ?) as t(id, qty) where (i.id = t.id)",
+			statement: Select(Ref("*"),
+				From(RefAs("items", "i")),
+				FromFunc(Func("unnest", Placeholder(), Placeholder()), "t", "id", "qty"),
+				Where(Equals(Ref("i.id"), Ref("t.id"))),
+			),
+		},
+		{
+			description: "quoted alias",
+			expected:    `select id from unnest($1) as "Order"(id)`,
+			dialect:     Postgres,
+			statement:   Select(Ref("id"), FromFunc(Func("unnest", Placeholder()), "Order", "id")),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			is.Equal(c.expected, c.statement.BuildWithDialect(c.dialect))
+		})
+	}
+}
+
+func TestSelectStar(t *testing.T) {
+	cases := []struct {
+		description string
+		expected    string
+		statement   Statement
+	}{
+		{
+			description: "nil columns",
+			expected:    "select * from users where (id = ?)",
+			statement:   Select(nil, From(Ref("users")), Where(Equals(Ref("id"), Placeholder()))),
+		},
+		{
+			description: "select star",
+			expected:    "select * from users order by id",
+			statement:   SelectStar(From(Ref("users")), OrderBy("id")),
+		},
+		{
+			description: "distinct",
+			expected:    "select distinct * from users",
+			statement:   SelectStar(Distinct(), From(Ref("users"))),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			is.Equal(c.expected, c.statement.Build())
+		})
+	}
+}
+
+func TestDefaultValues(t *testing.T) {
+	cases := []struct {
+		description string
+		expected    string
+		statement   Statement
+	}{
+		{
+			description: "default values",
+			expected:    "insert into events default values",
+			statement:   Insert(Ref("events"), DefaultValues()),
+		},
+		{
+			description: "default values returning",
+			expected:    "insert into events default values returning id",
+			statement:   Insert(Ref("events"), Returning(Ref("id")), DefaultValues()),
+		},
+		{
+			description: "default in values",
+			expected:    "insert into t (id, ts) values (?, default)",
+			statement:   Insert(Ref("t"), InsertColumns("id", "ts"), Values(Placeholder(), Default())),
+		},
+		{
+			description: "default in set",
+			expected:    "update t set ts = default where (id = ?)",
+			statement:   Update(Ref("t"), Set(Equals(Ref("ts"), Default())), Where(Equals(Ref("id"), Placeholder()))),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			is.Equal(c.expected, c.statement.Build())
+		})
+	}
+}
+
+func TestTypedEquals(t *testing.T) {
+	is := is.New(t)
+	ts := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	sql, args := Select(Ref("*"), From(Ref("events")), Where(
+		EqualsInt("id", 1),
+		EqualsString("kind", "click"),
+		EqualsTime("created_at", ts),
+	)).BuildArgsWithDialect(Postgres)
+
+	is.Equal("select * from events where (id = $1 and kind = $2 and created_at = $3)", sql)
+	is.Equal([]interface{}{1, "click", ts}, args)
+}
+
+func TestJoinSubselect(t *testing.T) {
+	totals := Select(
+		Columns(Ref("user_id"), As(Sum(Ref("amount")), "total")),
+		From(Ref("orders")),
+		Where(Equals(Ref("state"), Arg("paid"))),
+		GroupBy("user_id"),
+	)
+
+	cases := []struct {
+		description string
+		expected    string
+		args        []interface{}
+		statement   Statement
+	}{
+		{
+			description: "join",
+			expected:    "select u.id, o.total from users as u join (select user_id, sum(amount) as total from orders where (state = $1) group by user_id) as o on o.user_id = u.id where (u.team = $2)",
+			args:        []interface{}{"paid", "a"},
+			statement: Select(Columns(Ref("u.id"), Ref("o.total")),
+				From(RefAs("users", "u")),
+				JoinSubselect(totals, "o", Equals(Ref("o.user_id"), Ref("u.id"))),
+				Where(Equals(Ref("u.team"), Arg("a"))),
+			),
+		},
+		{
+			description: "left join with quoted alias",
+			expected:    `select * from users as u left join (select user_id, sum(amount) as total from orders where (state = $1) group by user_id) as "order totals" on "order totals".user_id = u.id`,
+			args:        []interface{}{"paid"},
+			statement: Select(Ref("*"),
+				From(RefAs("users", "u")),
+				LeftJoinSubselect(totals, "order totals", Equals(Ref(`"order totals".user_id`), Ref("u.id"))),
+			),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			sql, args := c.statement.BuildArgsWithDialect(Postgres)
+			is.Equal(c.expected, sql)
+			is.Equal(c.args, args)
+		})
+	}
+}
+
+func TestILike(t *testing.T) {
+	cases := []struct {
+		description string
+		expected    string
+		statement   Statement
+	}{
+		{
+			description: "ilike",
+			expected:    "select * from users where (name ilike $1)",
+			statement:   Select(Ref("*"), From(Ref("users")), Where(ILike(Ref("name"), Placeholder()))),
+		},
+		{
+			description: "not ilike",
+			expected:    "select * from users where (name not ilike $1 and email ilike $2)",
+			statement: Select(Ref("*"), From(Ref("users")), Where(
+				NotILike(Ref("name"), Placeholder()),
+				ILike(Ref("email"), Placeholder()),
+			)),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			is.Equal(c.expected, c.statement.BuildWithDialect(Postgres))
+		})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	cases := []struct {
+		description string
+		expected    string
+		statement   Statement
+	}{
+		{
+			description: "single table",
+			expected:    "truncate table users",
+			statement:   Truncate(Ref("users")),
+		},
+		{
+			description: "many tables with options",
+			expected:    "truncate table a, b restart identity cascade",
+			statement:   Truncate(Columns(Ref("a"), Ref("b")), Cascade(), RestartIdentity()),
+		},
+		{
+			description: "options used twice",
+			expected:    "truncate table a cascade",
+			statement:   Truncate(Ref("a"), Cascade(), Cascade()),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			is.Equal(c.expected, c.statement.Build())
+			is.NoErr(c.statement.Validate())
+		})
+	}
+}
+
+func TestBitwise(t *testing.T) {
+	cases := []struct {
+		description string
+		expected    string
+		dialect     Dialect
+		expr        Expression
+	}{
+		{
+			description: "and in a comparison",
+			expected:    "(flags & 4) = 4",
+			expr:        Equals(BitAnd(Ref("flags"), Ref("4")), Ref("4")),
+		},
+		{
+			description: "or",
+			expected:    "(flags | ?)",
+			expr:        BitOr(Ref("flags"), Placeholder()),
+		},
+		{
+			description: "xor",
+			expected:    "(a ^ b)",
+			expr:        BitXor(Ref("a"), Ref("b")),
+		},
+		{
+			description: "postgres xor",
+			expected:    "(a # b)",
+			dialect:     Postgres,
+			expr:        BitXor(Ref("a"), Ref("b")),
+		},
+		{
+			description: "shifts",
+			expected:    "((1 << n) >> 2)",
+			expr:        ShiftRight(ShiftLeft(Ref("1"), Ref("n")), Ref("2")),
+		},
+		{
+			description: "mixed precedence",
+			expected:    "((flags & (1 << ?)) | 8)",
+			expr:        BitOr(BitAnd(Ref("flags"), ShiftLeft(Ref("1"), Placeholder())), Ref("8")),
+		},
+		{
+			description: "not",
+			expected:    "(flags & (~4))",
+			expr:        BitAnd(Ref("flags"), BitNot(Ref("4"))),
+		},
+		{
+			description: "neg",
+			expected:    "(-balance)",
+			expr:        Neg(Ref("balance")),
+		},
+		{
+			description: "double neg",
+			expected:    "(-(-balance))",
+			expr:        Neg(Neg(Ref("balance"))),
+		},
+		{
+			description: "neg of infix",
+			expected:    "(-(a + b)) < ?",
+			expr:        Less(Neg(Add(Ref("a"), Ref("b"))), Placeholder()),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			is.Equal(c.expected, NewBuildContext(c.dialect).Build(c.expr))
+		})
+	}
+}
+
+func TestGroupingSets(t *testing.T) {
+	cases := []struct {
+		description string
+		expected    string
+		statement   Statement
+	}{
+		{
+			description: "rollup",
+			expected:    "select region, product, sum(amount) from sales group by rollup (region, product)",
+			statement:   Select(Columns(Ref("region"), Ref("product"), Sum(Ref("amount"))), From(Ref("sales")), GroupByRollup("region", "product")),
+		},
+		{
+			description: "cube",
+			expected:    "select region, product, sum(amount) from sales group by cube (region, product)",
+			statement:   Select(Columns(Ref("region"), Ref("product"), Sum(Ref("amount"))), From(Ref("sales")), GroupByCube("region", "product")),
+		},
+		{
+			description: "grouping sets",
+			expected:    "select region, product, sum(amount) from sales group by grouping sets ((region, product), (region), ()) having (sum(amount) > ?)",
+			statement: Select(Columns(Ref("region"), Ref("product"), Sum(Ref("amount"))), From(Ref("sales")),
+				Having(Greater(Sum(Ref("amount")), Placeholder())),
+				GroupingSets([]string{"region", "product"}, []string{"region"}, []string{}),
+			),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			sql := c.statement.Build()
+			is.Equal(c.expected, sql)
+			is.Equal(strings.Count(sql, "("), strings.Count(sql, ")"))
+			is.NoErr(c.statement.Validate())
+		})
+	}
+}
+
+func TestValuesTable(t *testing.T) {
+	lookup := ValuesTable([][]Expression{
+		{Arg(1), Arg("a")},
+		{Arg(2), Arg("b")},
+	}, "t", "id", "name")
+
+	cases := []struct {
+		description string
+		expected    string
+		args        []interface{}
+		statement   Statement
+	}{
+		{
+			description: "from",
+			expected:    "select * from (values ($1, $2), ($3, $4)) as t(id, name)",
+			args:        []interface{}{1, "a", 2, "b"},
+			statement:   Select(Ref("*"), From(lookup)),
+		},
+		{
+			description: "join",
+			expected:    "select u.*, t.name from users as u join (values ($1, $2), ($3, $4)) as t(id, name) on t.id = u.id where (u.team = $5)",
+			args:        []interface{}{1, "a", 2, "b", "x"},
+			statement: Select(Columns(Ref("u.*"), Ref("t.name")),
+				From(RefAs("users", "u")),
+				Join(lookup, Equals(Ref("t.id"), Ref("u.id"))),
+				Where(Equals(Ref("u.team"), Arg("x"))),
+			),
+		},
+		{
+			description: "without columns",
+			expected:    "select * from (values (1), (2)) as t",
+			statement:   Select(Ref("*"), From(ValuesTable([][]Expression{{Ref("1")}, {Ref("2")}}, "t"))),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			sql, args := c.statement.BuildArgsWithDialect(Postgres)
+			is.Equal(c.expected, sql)
+			is.Equal(c.args, args)
+		})
+	}
+}
+
+func TestValuesTableInvalid(t *testing.T) {
+	cases := []struct {
+		description string
+		err         error
+		statement   Statement
+	}{
+		{
+			description: "ragged rows",
+			err:         ErrRowLength,
+			statement:   Select(Ref("*"), From(ValuesTable([][]Expression{{Ref("1"), Ref("2")}, {Ref("3")}}, "t"))),
+		},
+		{
+			description: "no rows",
+			err:         ErrEmptyClause,
+			statement:   Select(Ref("*"), From(ValuesTable(nil, "t"))),
+		},
+		{
+			description: "joined",
+			err:         ErrRowLength,
+			statement:   Select(Ref("*"), From(Ref("users")), Join(ValuesTable([][]Expression{{Ref("1")}, {}}, "t"), Equals(Ref("t.id"), Ref("users.id")))),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			is.True(errors.Is(c.statement.Err(), c.err))
+			is.Equal("", c.statement.Build())
+		})
+	}
+}
+
+func TestClone(t *testing.T) {
+	is := is.New(t)
+
+	base := Select(Ref("*"), From(Ref("items")), Where(Equals(Ref("a"), Placeholder())))
+	// leave spare capacity so appends to a plain copy would share it
+	base.Clauses = append(make([]Clause, 0, 10), base.Clauses...)
+
+	clone := base.Clone()
+	opts := []StatementOption{Where(Equals(Ref("b"), Placeholder())), Limit(1), Distinct()}
+	for _, opt := range opts {
+		opt(&clone)
+	}
+
+	other := base.Clone()
+	OrderBy("id")(&other)
+
+	is.Equal("select * from items where (a = ?)", base.Build())
+	is.Equal("select distinct * from items where (a = ?) and (b = ?) limit 1", clone.Build())
+	is.Equal("select * from items where (a = ?) order by id", other.Build())
+}
+
+func TestCloneOnConflict(t *testing.T) {
+	is := is.New(t)
+
+	base := Insert(Ref("items"), InsertColumns("id", "name"), Values(Placeholder(), Placeholder()),
+		OnConflict("id"), DoUpdate(Equals(Ref("name"), Ref("excluded.name"))))
+	// leave spare capacity in the assignments
+	oc := base.Clauses[len(base.Clauses)-1].(onConflictClause)
+	oc.update = append(make([]Expression, 0, 10), oc.update...)
+	base.Clauses[len(base.Clauses)-1] = oc
+
+	a := base.Clone()
+	DoUpdate(Equals(Ref("a"), Ref("excluded.a")))(&a)
+
+	b := base.Clone()
+	DoUpdate(Equals(Ref("b"), Ref("excluded.b")))(&b)
+
+	is.Equal("insert into items (id, name) values (?, ?) on conflict (id) do update set name = excluded.name", base.Build())
+	is.Equal("insert into items (id, name) values (?, ?) on conflict (id) do update set name = excluded.name, a = excluded.a", a.Build())
+	is.Equal("insert into items (id, name) values (?, ?) on conflict (id) do update set name = excluded.name, b = excluded.b", b.Build())
+}
+
+func TestLikeEscape(t *testing.T) {
+	cases := []struct {
+		description string
+		expected    string
+		dialect     Dialect
+		expr        Expression
+	}{
+		{
+			description: "backslash",
+			expected:    `name like ? escape '\'`,
+			expr:        LikeEscape(Ref("name"), Placeholder(), `\`),
+		},
+		{
+			description: "custom character",
+			expected:    "code like $1 escape '!'",
+			dialect:     Postgres,
+			expr:        LikeEscape(Ref("code"), Placeholder(), "!"),
+		},
+		{
+			description: "mysql backslash",
+			expected:    `name like ? escape '\\'`,
+			dialect:     MySQL,
+			expr:        LikeEscape(Ref("name"), Placeholder(), `\`),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			is.Equal(c.expected, NewBuildContext(c.dialect).Build(c.expr))
+		})
+	}
+}
+
+func TestBulkValues(t *testing.T) {
+	is := is.New(t)
+
+	st := Insert(Ref("items"), InsertColumns("id", "name"), BulkValues([][]interface{}{
+		{1, "a"},
+		{2, "b"},
+		{3, "c"},
+	}), Returning(Ref("id")))
+
+	sql, args := st.BuildArgsWithDialect(Postgres)
+	is.Equal("insert into items (id, name) values ($1, $2), ($3, $4), ($5, $6) returning id", sql)
+	is.Equal([]interface{}{1, "a", 2, "b", 3, "c"}, args)
+	is.Equal(6, st.NumPlaceholders())
+	is.NoErr(st.Err())
+}
+
+func TestBulkValuesRowLength(t *testing.T) {
+	is := is.New(t)
+
+	st := Insert(Ref("items"), InsertColumns("id", "name"), BulkValues([][]interface{}{
+		{1, "a"},
+		{2},
+	}))
+
+	is.True(errors.Is(st.Err(), ErrRowLength))
+	is.Equal("", st.Build())
+}
+
+func TestJoinLateral(t *testing.T) {
+	latest := Select(Columns(Ref("o.id"), Ref("o.total")),
+		From(RefAs("orders", "o")),
+		Where(Equals(Ref("o.user_id"), Ref("u.id")), Greater(Ref("o.total"), Arg(10))),
+		OrderByExpr(Desc(Ref("o.created_at"))),
+		Limit(1),
+	)
+
+	cases := []struct {
+		description string
+		expected    string
+		args        []interface{}
+		statement   Statement
+	}{
+		{
+			description: "join lateral on true",
+			expected:    "select u.id, l.total from users as u join lateral (select o.id, o.total from orders as o where (o.user_id = u.id and o.total > $1) order by o.created_at desc limit 1) as l on true where (u.team = $2)",
+			args:        []interface{}{10, "a"},
+			statement: Select(Columns(Ref("u.id"), Ref("l.total")),
+				From(RefAs("users", "u")),
+				JoinLateral(latest, "l"),
+				Where(Equals(Ref("u.team"), Arg("a"))),
+			),
+		},
+		{
+			description: "left join lateral with predicates",
+			expected:    "select * from users as u left join lateral (select o.id, o.total from orders as o where (o.user_id = u.id and o.total > $1) order by o.created_at desc limit 1) as l on l.id is not null",
+			args:        []interface{}{10},
+			statement: Select(Ref("*"),
+				From(RefAs("users", "u")),
+				LeftJoinLateral(latest, "l", IsNotNull(Ref("l.id"))),
+			),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			sql, args := c.statement.BuildArgsWithDialect(Postgres)
+			is.Equal(c.expected, sql)
+			is.Equal(c.args, args)
+		})
+	}
+}
+
+func TestString(t *testing.T) {
+	is := is.New(t)
+
+	st := Select(Ref("*"), From(Ref("items")), Where(Equals(Ref("id"), Placeholder())))
+
+	is.Equal(st.Build(), st.String())
+	is.Equal("select * from items where (id = ?)", fmt.Sprint(st))
+	is.Equal("query: select * from items where (id = ?)", fmt.Sprintf("query: %s", st))
+}
+
+func TestComment(t *testing.T) {
+	cases := []struct {
+		description string
+		expected    string
+		statement   Statement
+	}{
+		{
+			description: "sorted keys",
+			expected:    "select * from users where (id = ?) limit 1 /* route='/users',service='api' */",
+			statement: Select(Ref("*"), From(Ref("users")),
+				Comment(map[string]string{"service": "api", "route": "/users"}),
+				Where(Equals(Ref("id"), Placeholder())),
+				Limit(1),
+			),
+		},
+		{
+			description: "sanitized",
+			expected:    `delete from users /* note='a * / b / * c \'d\'' */`,
+			statement:   Delete(Ref("users"), Comment(map[string]string{"note": "a */ b /* c 'd'"})),
+		},
+		{
+			description: "replaced",
+			expected:    "select 1 /* b='2' */",
+			statement:   Select(Ref("1"), Comment(map[string]string{"a": "1"}), Comment(map[string]string{"b": "2"})),
+		},
+		{
+			description: "compound",
+			expected:    "(select 1) union (select 2) /* a='1' */",
+			statement:   Union(Select(Ref("1")), Select(Ref("2")), Comment(map[string]string{"a": "1"})),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			is.Equal(c.expected, c.statement.Build())
+			is.NoErr(c.statement.Validate())
+		})
+	}
+}
+
+func TestInSubquery(t *testing.T) {
+	sub := Select(Ref("user_id"), From(Ref("admins")))
+
+	cases := []struct {
+		description string
+		expected    string
+		expr        Expression
+	}{
+		{
+			description: "subquery",
+			expected:    "id in (select user_id from admins)",
+			expr:        InSubquery(Ref("id"), sub),
+		},
+		{
+			description: "not in subquery",
+			expected:    "id not in (select user_id from admins)",
+			expr:        NotInSubquery(Ref("id"), sub),
+		},
+		{
+			description: "in with subquery",
+			expected:    "id in (select user_id from admins)",
+			expr:        In(Ref("id"), sub),
+		},
+		{
+			description: "in with wrapped subquery",
+			expected:    "id in ((select user_id from admins))",
+			expr:        In(Ref("id"), ScalarSubquery(sub)),
+		},
+		{
+			description: "in with list",
+			expected:    "id in (?, ?)",
+			expr:        In(Ref("id"), Columns(Placeholder(), Placeholder())),
+		},
+		{
+			description: "in with single placeholder",
+			expected:    "id in (?)",
+			expr:        In(Ref("id"), Placeholder()),
+		},
+		{
+			description: "compound subquery",
+			expected:    "id in ((select user_id from admins) union (select user_id from owners))",
+			expr:        InSubquery(Ref("id"), Union(sub, Select(Ref("user_id"), From(Ref("owners"))))),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			is.Equal(c.expected, c.expr.Build())
+		})
+	}
+}
+
+func TestInSubqueryArgs(t *testing.T) {
+	is := is.New(t)
+
+	sub := Select(Ref("user_id"), From(Ref("admins")), Where(Equals(Ref("org"), Placeholder())))
+	st := Select(Ref("*"), From(Ref("users")), Where(Equals(Ref("active"), Placeholder()), InSubquery(Ref("id"), sub)))
+
+	is.Equal("select * from users where (active = $1 and id in (select user_id from admins where (org = $2)))", st.BuildWithDialect(Postgres))
+}
+
+func TestCollate(t *testing.T) {
+	cases := []struct {
+		description string
+		expected    string
+		dialect     Dialect
+		expr        Expression
+	}{
+		{
+			description: "left side",
+			expected:    `name collate "C" = ?`,
+			dialect:     SQLite,
+			expr:        Equals(Collate(Ref("name"), "C"), Placeholder()),
+		},
+		{
+			description: "right side",
+			expected:    `name = $1 collate "C"`,
+			dialect:     Postgres,
+			expr:        Equals(Ref("name"), Collate(Placeholder(), "C")),
+		},
+		{
+			description: "mysql",
+			expected:    "name collate `utf8mb4_bin` = ?",
+			dialect:     MySQL,
+			expr:        Equals(Collate(Ref("name"), "utf8mb4_bin"), Placeholder()),
+		},
+		{
+			description: "escaped",
+			expected:    `name collate "a""b"`,
+			dialect:     Postgres,
+			expr:        Collate(Ref("name"), `a"b`),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			is.Equal(c.expected, NewBuildContext(c.dialect).Build(c.expr))
+		})
+	}
+}
+
+func TestFrameInvalidMode(t *testing.T) {
+	is := is.New(t)
+
+	defer func() {
+		is.True(recover() != nil)
+	}()
+
+	Frame("between", "unbounded preceding", "current row")
+}
+
+func TestNamedWindow(t *testing.T) {
+	cases := []struct {
+		description string
+		expected    string
+		statement   Statement
+	}{
+		{
+			description: "single window",
+			expected:    "select sum(total) over w from sales window w as (partition by shop order by day)",
+			statement: Select(Window("sum(total)", UseWindow("w")), From(Ref("sales")),
+				NamedWindow("w", PartitionBy("shop"), OrderByC("day")),
+			),
+		},
+		{
+			description: "multiple windows",
+			expected: "select sum(total) over w, avg(total) over m from sales group by shop, day having (count(*) > 1) " +
+				"window w as (partition by shop), m as (order by day rows between 6 preceding and current row) order by day",
+			statement: Select(
+				Columns(Window("sum(total)", UseWindow("w")), Window("avg(total)", UseWindow("m"))),
+				From(Ref("sales")),
+				OrderBy("day"),
+				NamedWindow("w", PartitionBy("shop")),
+				GroupBy("shop", "day"),
+				Having(Greater(Ref("count(*)"), Ref("1"))),
+				NamedWindow("m", OrderByC("day"), Frame("rows", "6 preceding", "current row")),
+			),
+		},
+		{
+			description: "extended window",
+			expected:    "select row_number() over (w order by day) from sales window w as (partition by shop)",
+			statement: Select(Window("row_number()", UseWindow("w"), OrderByC("day")), From(Ref("sales")),
+				NamedWindow("w", PartitionBy("shop")),
+			),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			is.Equal(c.expected, c.statement.Build())
+			is.NoErr(c.statement.Validate())
+		})
+	}
+}
+
+func TestIsDistinctFrom(t *testing.T) {
+	cases := []struct {
+		description string
+		expected    string
+		statement   Statement
+	}{
+		{
+			description: "is distinct from",
+			expected:    "select * from items where (a is distinct from ?)",
+			statement:   Select(Ref("*"), From(Ref("items")), Where(IsDistinctFrom(Ref("a"), Placeholder()))),
+		},
+		{
+			description: "is not distinct from",
+			expected:    "select * from items where (a is not distinct from ?)",
+			statement:   Select(Ref("*"), From(Ref("items")), Where(IsNotDistinctFrom(Ref("a"), Placeholder()))),
+		},
+		{
+			description: "with or",
+			expected:    "select * from items where ((a is not distinct from ? or b is distinct from c) and d = ?)",
+			statement: Select(Ref("*"), From(Ref("items")), Where(
+				Or(IsNotDistinctFrom(Ref("a"), Placeholder()), IsDistinctFrom(Ref("b"), Ref("c"))),
+				Equals(Ref("d"), Placeholder()),
+			)),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			is.Equal(c.expected, c.statement.Build())
+		})
+	}
+}
+
+func TestBarePredicate(t *testing.T) {
+	cases := []struct {
+		description string
+		expected    string
+		statement   Statement
+	}{
+		{
+			description: "bare ref",
+			expected:    "select * from users where (active)",
+			statement:   Select(Ref("*"), From(Ref("users")), Where(Ref("active"))),
+		},
+		{
+			description: "bool",
+			expected:    "select * from users where (active and not (banned))",
+			statement:   Select(Ref("*"), From(Ref("users")), Where(Bool("active"), Not(Bool("banned")))),
+		},
+		{
+			description: "bool in or",
+			expected:    "select * from users where ((admin or team = ?))",
+			statement:   Select(Ref("*"), From(Ref("users")), Where(Or(Bool("admin"), Equals(Ref("team"), Placeholder())))),
+		},
+		{
+			description: "bool in join",
+			expected:    "select * from users join teams on teams.id = users.team_id and teams.active",
+			statement:   Select(Ref("*"), From(Ref("users")), Join(Ref("teams"), Equals(Ref("teams.id"), Ref("users.team_id")), Bool("teams.active"))),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			is.Equal(c.expected, c.statement.Build())
+		})
+	}
+}
+
+func TestUpdateSubquerySet(t *testing.T) {
+	rank := Select(Count(Ref("*")), From(Ref("scores s")), Where(Greater(Ref("s.points"), Ref("t.points")), Equals(Ref("s.season"), Placeholder())))
+	totals := Select(Columns(Sum(Ref("points")), Max(Ref("played_at"))), From(Ref("games g")), Where(Equals(Ref("g.team_id"), Ref("t.id"))))
+
+	cases := []struct {
+		description string
+		expected    string
+		statement   Statement
+	}{
+		{
+			description: "scalar subquery",
+			expected:    "update teams t set rank = (select count(*) from scores s where (s.points > t.points and s.season = $1)) where (t.league = $2)",
+			statement: Update(Ref("teams t"),
+				Set(Equals(Ref("rank"), ScalarSubquery(rank))),
+				Where(Equals(Ref("t.league"), Placeholder())),
+			),
+		},
+		{
+			description: "row assignment",
+			expected:    "update teams t set (points, last_played) = (select sum(points), max(played_at) from games g where (g.team_id = t.id)), updated_at = $1",
+			statement: Update(Ref("teams t"),
+				Set(AssignRow([]string{"points", "last_played"}, totals), Equals(Ref("updated_at"), Placeholder())),
+			),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			is.Equal(c.expected, c.statement.BuildWithDialect(Postgres))
+		})
+	}
+}
+
+func TestTables(t *testing.T) {
+	cases := []struct {
+		description string
+		expected    string
+		statement   Statement
+	}{
+		{
+			description: "tables",
+			expected:    "select * from users u, teams as t, roles where (u.team_id = t.id)",
+			statement:   Select(Ref("*"), Tables(Ref("users u"), RefAs("teams", "t"), Ref("roles")), Where(Equals(Ref("u.team_id"), Ref("t.id")))),
+		},
+		{
+			description: "adds to from",
+			expected:    "select * from accounts, users, teams",
+			statement:   Select(Ref("*"), From(Ref("accounts")), Tables(Ref("users"), Ref("teams"))),
+		},
+		{
+			description: "adds to from subselect",
+			expected:    "select * from (select id from a) as s, b",
+			statement:   Select(Ref("*"), FromSubselect(Select(Ref("id"), From(Ref("a"))), "s"), Tables(Ref("b"))),
+		},
+		{
+			description: "same as from",
+			expected:    Select(Ref("*"), From(Ref("a"), Ref("b"))).Build(),
+			statement:   Select(Ref("*"), Tables(Ref("a"), Ref("b"))),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			is.Equal(c.expected, c.statement.Build())
+		})
+	}
+}
+
+func TestTablesNil(t *testing.T) {
+	is := is.New(t)
+
+	st := Select(Ref("*"), Tables(Ref("users"), nil))
+	is.True(errors.Is(st.Err(), ErrNilExpression))
+	is.Equal("", st.Build())
+
+	st = Select(Ref("*"), Tables())
+	is.True(errors.Is(st.Err(), ErrEmptyClause))
+}
+
+func TestOrderByOrdinal(t *testing.T) {
+	cases := []struct {
+		description string
+		expected    string
+		statement   Statement
+	}{
+		{
+			description: "positions",
+			expected:    "select kind, count(*) from items group by kind order by 2, 1",
+			statement:   Select(Columns(Ref("kind"), CountAll()), From(Ref("items")), GroupBy("kind"), OrderByOrdinal(2, 1)),
+		},
+		{
+			description: "with direction",
+			expected:    "select kind, count(*) from items group by kind order by 2 desc",
+			statement:   Select(Columns(Ref("kind"), CountAll()), From(Ref("items")), GroupBy("kind"), OrderByExpr(Desc(Ordinal(2)))),
+		},
+		{
+			description: "mixed with columns",
+			expected:    "select kind, count(*) from items group by kind order by 2, kind",
+			statement:   Select(Columns(Ref("kind"), CountAll()), From(Ref("items")), GroupBy("kind"), OrderByOrdinal(2), OrderBy("kind")),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			is.Equal(c.expected, c.statement.Build())
+		})
+	}
+}
+
+func TestOrderByOrdinalInvalid(t *testing.T) {
+	is := is.New(t)
+
+	defer func() {
+		is.True(recover() != nil)
+	}()
+
+	OrderByOrdinal(1, 0)
+}
+
+func TestOrderByMultipleUses(t *testing.T) {
+	is := is.New(t)
+
+	base := Select(Ref("*"), From(Ref("items")), OrderBy("a"))
+
+	a := base.Clone()
+	OrderBy("b")(&a)
+
+	b := base.Clone()
+	OrderByOrdinal(3)(&b)
+
+	is.Equal("select * from items order by a", base.Build())
+	is.Equal("select * from items order by a, b", a.Build())
+	is.Equal("select * from items order by a, 3", b.Build())
+	is.NoErr(a.Validate())
+}
+
+func TestTableStmt(t *testing.T) {
+	cases := []struct {
+		description string
+		expected    string
+		dialect     Dialect
+		statement   Statement
+	}{
+		{
+			description: "postgres",
+			expected:    "table users",
+			dialect:     Postgres,
+			statement:   TableStmt(Ref("users")),
+		},
+		{
+			description: "fallback",
+			expected:    "select * from users",
+			dialect:     SQLite,
+			statement:   TableStmt(Ref("users")),
+		},
+		{
+			description: "order by and limit",
+			expected:    "table users order by id limit 10",
+			dialect:     Postgres,
+			statement:   TableStmt(Ref("users"), OrderBy("id"), Limit(10)),
+		},
+		{
+			description: "union",
+			expected:    "(table users) union (table admins)",
+			dialect:     Postgres,
+			statement:   Union(TableStmt(Ref("users")), TableStmt(Ref("admins"))),
+		},
+		{
+			description: "union fallback",
+			expected:    "(select * from users) union (select * from admins)",
+			dialect:     MySQL,
+			statement:   Union(TableStmt(Ref("users")), TableStmt(Ref("admins"))),
+		},
+		{
+			description: "subselect",
+			expected:    "select u.id from (table users) as u where (u.id = $1)",
+			dialect:     Postgres,
+			statement:   Select(Ref("u.id"), FromSubselect(TableStmt(Ref("users")), "u"), Where(Equals(Ref("u.id"), Placeholder()))),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			is.Equal(c.expected, c.statement.BuildWithDialect(c.dialect))
+			is.NoErr(c.statement.Validate())
+		})
+	}
+}
+
+func TestTableStmtValidate(t *testing.T) {
+	is := is.New(t)
+
+	is.True(errors.Is(TableStmt(Ref("users"), Where(Bool("active"))).Validate(), ErrInvalidClause))
+	is.True(errors.Is(TableStmt(nil).Err(), ErrNilExpression))
+}
+
+func TestArray(t *testing.T) {
+	cases := []struct {
+		description string
+		expected    string
+		expr        Expression
+	}{
+		{
+			description: "literal",
+			expected:    "array[1, 2, 3]",
+			expr:        Array(Ref("1"), Ref("2"), Ref("3")),
+		},
+		{
+			description: "empty",
+			expected:    "array[]",
+			expr:        Array(),
+		},
+		{
+			description: "empty typed",
+			expected:    "array[]::int[]",
+			expr:        CastOp(Array(), "int[]"),
+		},
+		{
+			description: "any of placeholders",
+			expected:    "id = any(array[$1, $2])",
+			expr:        AnyArray(Ref("id"), Array(Placeholder(), Placeholder())),
+		},
+		{
+			description: "any of bound slice",
+			expected:    "id = any($1)",
+			expr:        AnyArray(Ref("id"), Arg([]int{1, 2})),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			is.Equal(c.expected, NewBuildContext(Postgres).Build(c.expr))
+		})
+	}
+}
+
+func TestAnyArrayStatement(t *testing.T) {
+	is := is.New(t)
+
+	st := Select(Ref("*"), From(Ref("items")), Where(AnyArray(Ref("id"), Array(Arg(1), Arg(2))), Equals(Ref("kind"), Arg("a"))))
+	sql, args := st.BuildArgsWithDialect(Postgres)
+
+	is.Equal("select * from items where (id = any(array[$1, $2]) and kind = $3)", sql)
+	is.Equal([]interface{}{1, 2, "a"}, args)
+}
+
+type fakeClause struct {
+	kind ClauseKind
+}
+
+func (c fakeClause) Kind() ClauseKind  { return c.kind }
+func (c fakeClause) Delimeter() string { return " " }
+func (c fakeClause) Build() string     { return "fake" }
+
+func TestClauseKindOrder(t *testing.T) {
+	is := is.New(t)
+
+	// every clause kind in the order it's built in a statement
+	kinds := []struct {
+		kind ClauseKind
+		name string
+	}{
+		{_InsertColumnsClause, "columns"},
+		{_ValuesClause, "values"},
+		{_DefaultValuesClause, "default values"},
+		{_OnConflictClause, "on conflict"},
+		{_FromClause, "from"},
+		{_JoinClause, "join"},
+		{_LeftJoinClause, "left join"},
+		{_InnerJoinClause, "inner join"},
+		{_RightJoinClause, "right join"},
+		{_FullJoinClause, "full join"},
+		{_CrossJoinClause, "cross join"},
+		{_SetClause, "set"},
+		{_UpdateFromClause, "from"},
+		{_UsingClause, "using"},
+		{_WhereClause, "where"},
+		{_GroupByClause, "group by"},
+		{_HavingClause, "having"},
+		{_WindowClause, "window"},
+		{_OrderByClause, "order by"},
+		{_LimitClause, "limit"},
+		{_OffsetClause, "offset"},
+		{_FetchClause, "fetch"},
+		{_ReturningClause, "returning"},
+		{_LockingClause, "locking"},
+		{_RestartIdentityClause, "restart identity"},
+		{_CascadeClause, "cascade"},
+		{_CommentClause, "comment"},
+	}
+
+	is.Equal(numClauseKinds-1, len(kinds)) // every clause kind is listed
+
+	for i, k := range kinds {
+		is.Equal(ClauseKind(i+1), k.kind)
+		is.Equal(k.name, k.kind.String())
+	}
+}
+
+func TestClauseOrder(t *testing.T) {
+	is := is.New(t)
+
+	// options are given in reverse so the statement has to put them in order
+	st := Select(Ref("*"),
+		Comment(map[string]string{"a": "1"}),
+		ForUpdate(),
+		Offset(20),
+		Limit(10),
+		OrderBy("id"),
+		NamedWindow("w", PartitionBy("kind")),
+		Having(Greater(CountAll(), Ref("1"))),
+		GroupBy("kind", "id"),
+		Where(Bool("active")),
+		LeftJoin(Ref("tags"), Equals(Ref("tags.item_id"), Ref("items.id"))),
+		Join(Ref("owners"), Equals(Ref("owners.id"), Ref("items.owner_id"))),
+		From(Ref("items")),
+	)
+
+	is.Equal("select * from items left join tags on tags.item_id = items.id join owners on owners.id = items.owner_id "+
+		"where (active) group by kind, id having (count(*) > 1) window w as (partition by kind) order by id "+
+		"limit 10 offset 20 for update /* a='1' */", st.Build())
+}
+
+func TestUnknownClauseKind(t *testing.T) {
+	for _, kind := range []ClauseKind{_unknownClause, ClauseKind(numClauseKinds), ClauseKind(numClauseKinds + 10)} {
+		t.Run(kind.String(), func(t *testing.T) {
+			is := is.New(t)
+
+			defer func() {
+				is.True(recover() != nil)
+			}()
+
+			st := Select(Ref("*"), From(Ref("items")))
+			st.Clauses = append(st.Clauses, fakeClause{kind: kind})
+			st.Build()
+		})
+	}
+}
+
+func TestWhereAll(t *testing.T) {
+	var nilFunc ContextExpressionFunc
+
+	cases := []struct {
+		description string
+		expected    string
+		statement   Statement
+	}{
+		{
+			description: "empty where",
+			expected:    "select * from items",
+			statement:   Select(Ref("*"), From(Ref("items")), Where()),
+		},
+		{
+			description: "empty where with other where",
+			expected:    "select * from items where (a = ?)",
+			statement:   Select(Ref("*"), From(Ref("items")), Where(), Where(Equals(Ref("a"), Placeholder()))),
+		},
+		{
+			description: "empty having",
+			expected:    "select kind from items group by kind",
+			statement:   Select(Ref("kind"), From(Ref("items")), GroupBy("kind"), Having()),
+		},
+		{
+			description: "empty having with other having",
+			expected:    "select kind from items group by kind having (count(*) > ?)",
+			statement:   Select(Ref("kind"), From(Ref("items")), GroupBy("kind"), Having(), Having(Greater(CountAll(), Placeholder()))),
+		},
+		{
+			description: "filters",
+			expected:    "select * from items where (a = ? and b = ?)",
+			statement:   Select(Ref("*"), From(Ref("items")), WhereAll([]Expression{Equals(Ref("a"), Placeholder()), nil, Equals(Ref("b"), Placeholder()), nilFunc})),
+		},
+		{
+			description: "no filters",
+			expected:    "select * from items",
+			statement:   Select(Ref("*"), From(Ref("items")), WhereAll(nil)),
+		},
+		{
+			description: "all nil filters",
+			expected:    "select * from items",
+			statement:   Select(Ref("*"), From(Ref("items")), WhereAll([]Expression{nil, nilFunc})),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			is.Equal(c.expected, c.statement.Build())
+			is.NoErr(c.statement.Validate())
+		})
+	}
+}
+
+func TestGroupByExpr(t *testing.T) {
+	day := Func("date_trunc", Const("day"), Ref("created_at"))
+
+	cases := []struct {
+		description string
+		expected    string
+		args        []interface{}
+		statement   Statement
+	}{
+		{
+			description: "expression",
+			expected:    "select date_trunc('day', created_at), count(*) from events group by date_trunc('day', created_at)",
+			statement:   Select(Columns(day, CountAll()), From(Ref("events")), GroupByExpr(day)),
+		},
+		{
+			description: "placeholders",
+			expected:    "select date_trunc($1, created_at), count(*) from events where (kind = $2) group by date_trunc($3, created_at)",
+			args:        []interface{}{"hour", "click", "hour"},
+			statement: Select(
+				Columns(Func("date_trunc", Arg("hour"), Ref("created_at")), CountAll()),
+				From(Ref("events")),
+				GroupByExpr(Func("date_trunc", Arg("hour"), Ref("created_at"))),
+				Where(Equals(Ref("kind"), Arg("click"))),
+			),
+		},
+		{
+			description: "multiple uses",
+			expected:    "select kind, count(*) from events group by kind, date_trunc('day', created_at)",
+			statement:   Select(Columns(Ref("kind"), CountAll()), From(Ref("events")), GroupBy("kind"), GroupByExpr(day)),
+		},
+		{
+			description: "with rollup",
+			expected:    "select kind, count(*) from events group by date_trunc('day', created_at), rollup (kind, source)",
+			statement:   Select(Columns(Ref("kind"), CountAll()), From(Ref("events")), GroupByExpr(day), GroupByRollup("kind", "source")),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			sql, args := c.statement.BuildArgsWithDialect(Postgres)
+			is.Equal(c.expected, sql)
+			is.Equal(c.args, args)
+			is.NoErr(c.statement.Validate())
+		})
+	}
+}
+
+func TestAddWhere(t *testing.T) {
+	cases := []struct {
+		description string
+		expected    string
+		statement   Statement
+	}{
+		{
+			description: "no where",
+			expected:    "select * from items where (deleted_at is null)",
+			statement:   Select(Ref("*"), From(Ref("items"))),
+		},
+		{
+			description: "existing where",
+			expected:    "select * from items where (kind = ?) and (deleted_at is null) order by id",
+			statement:   Select(Ref("*"), From(Ref("items")), Where(Equals(Ref("kind"), Placeholder())), OrderBy("id")),
+		},
+		{
+			description: "update",
+			expected:    "update items set a = ? where (deleted_at is null)",
+			statement:   Update(Ref("items"), Set(Equals(Ref("a"), Placeholder()))),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			st := c.statement.Clone()
+			st.AddWhere(IsNull(Ref("deleted_at")))
+
+			is.Equal(c.expected, st.Build())
+			is.True(c.statement.Build() != st.Build()) // the original is left alone
+		})
+	}
+}
+
+func TestBulkUpdate(t *testing.T) {
+	is := is.New(t)
+
+	st := BulkUpdate("items", "id", []string{"id", "name", "price"}, [][]interface{}{
+		{1, "a", 10},
+		{2, "b", 20},
+	})
+	sql, args := st.BuildArgsWithDialect(Postgres)
+
+	is.Equal("update items set name = _bulk.name, price = _bulk.price "+
+		"from (values ($1, $2, $3), ($4, $5, $6)) as _bulk(id, name, price) where (items.id = _bulk.id)", sql)
+	is.Equal([]interface{}{1, "a", 10, 2, "b", 20}, args)
+	is.NoErr(st.Validate())
+}
+
+func TestBulkUpdateCast(t *testing.T) {
+	is := is.New(t)
+
+	st := BulkUpdate("items", "id", []string{"id", "name"}, [][]interface{}{
+		{CastOp(Arg(1), "int"), "a"},
+		{2, "b"},
+	})
+	sql, args := st.BuildArgsWithDialect(Postgres)
+
+	is.Equal("update items set name = _bulk.name "+
+		"from (values ($1::int, $2), ($3, $4)) as _bulk(id, name) where (items.id = _bulk.id)", sql)
+	is.Equal([]interface{}{1, "a", 2, "b"}, args)
+}
+
+func TestBulkUpdateErr(t *testing.T) {
+	cases := []struct {
+		description string
+		err         error
+		statement   Statement
+	}{
+		{
+			description: "no rows",
+			err:         ErrMissingClause,
+			statement:   BulkUpdate("items", "id", []string{"id", "name"}, nil),
+		},
+		{
+			description: "short row",
+			err:         ErrRowLength,
+			statement:   BulkUpdate("items", "id", []string{"id", "name"}, [][]interface{}{{1, "a"}, {2}}),
+		},
+		{
+			description: "missing key column",
+			err:         ErrInvalidClause,
+			statement:   BulkUpdate("items", "id", []string{"sku", "name"}, [][]interface{}{{1, "a"}}),
+		},
+		{
+			description: "nothing to set",
+			err:         ErrMissingClause,
+			statement:   BulkUpdate("items", "id", []string{"id"}, [][]interface{}{{1}}),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			is.True(errors.Is(c.statement.Err(), c.err))
+			is.Equal("", c.statement.Build())
+		})
+	}
+}
+
+func TestFromSubselectColumns(t *testing.T) {
+	counts := Select(Columns(Ref("user_id"), CountAll()), From(Ref("events")), Where(Equals(Ref("kind"), Placeholder())), GroupBy("user_id"))
+
+	cases := []struct {
+		description string
+		expected    string
+		statement   Statement
+	}{
+		{
+			description: "table alias",
+			expected:    "select * from (select user_id, count(*) from events where (kind = $1) group by user_id) as c where (c.user_id = $2)",
+			statement:   Select(Ref("*"), FromSubselect(counts, "c"), Where(Equals(Ref("c.user_id"), Placeholder()))),
+		},
+		{
+			description: "column aliases",
+			expected:    "select c.total from (select user_id, count(*) from events where (kind = $1) group by user_id) as c(user_id, total) where (c.total > $2)",
+			statement:   Select(Ref("c.total"), FromSubselect(counts, "c", "user_id", "total"), Where(Greater(Ref("c.total"), Placeholder()))),
+		},
+		{
+			description: "unknown column count",
+			expected:    "select * from (select * from events) as e(a, b, c)",
+			statement:   Select(Ref("*"), FromSubselect(Select(Ref("*"), From(Ref("events"))), "e", "a", "b", "c")),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			is.Equal(c.expected, c.statement.BuildWithDialect(Postgres))
+			is.NoErr(c.statement.Validate())
+		})
+	}
+}
+
+func TestFromSubselectColumnsErr(t *testing.T) {
+	is := is.New(t)
+
+	sub := Select(Columns(Ref("a"), Ref("b")), From(Ref("t")))
+
+	st := Select(Ref("*"), FromSubselect(sub, "s", "x"))
+	is.True(errors.Is(st.Err(), ErrInvalidClause))
+
+	st = Select(Ref("*"), FromSubselect(sub, "", "x", "y"))
+	is.True(errors.Is(st.Err(), ErrInvalidClause))
+}
+
+func TestNotInSafe(t *testing.T) {
+	cases := []struct {
+		description string
+		expected    string
+		expr        Expression
+	}{
+		{
+			description: "select",
+			expected:    "not exists (select 1 from (select user_id as value from bans where (active = $1)) as _not_in where (_not_in.value = users.id))",
+			expr:        NotInSafe(Ref("users.id"), Select(Ref("user_id"), From(Ref("bans")), Where(Equals(Ref("active"), Placeholder())))),
+		},
+		{
+			description: "select of columns",
+			expected:    "not exists (select 1 from (select distinct user_id as value from bans) as _not_in where (_not_in.value = $1))",
+			expr:        NotInSafe(Placeholder(), Select(Columns(Ref("user_id")), From(Ref("bans")), Distinct())),
+		},
+		{
+			description: "aliased column",
+			expected:    "not exists (select 1 from (select user_id as value from orders) as _not_in where (_not_in.value = id))",
+			expr:        NotInSafe(Ref("id"), Select(RefAs("user_id", "uid"), From(Ref("orders")))),
+		},
+		{
+			description: "aliased expression",
+			expected:    "not exists (select 1 from (select lower(email) as value from bans) as _not_in where (_not_in.value = email))",
+			expr:        NotInSafe(Ref("email"), Select(Columns(As(Func("lower", Ref("email")), "e")), From(Ref("bans")))),
+		},
+		{
+			description: "compound",
+			expected:    "not exists (select 1 from ((select user_id from bans) union (select user_id from mutes)) as _not_in(value) where (_not_in.value = id))",
+			expr:        NotInSafe(Ref("id"), Union(Select(Ref("user_id"), From(Ref("bans"))), Select(Ref("user_id"), From(Ref("mutes"))))),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			is.Equal(c.expected, NewBuildContext(Postgres).Build(c.expr))
+		})
+	}
+}
+
+func TestNotInSafeInvalid(t *testing.T) {
+	cases := []struct {
+		description string
+		sub         Statement
+	}{
+		{
+			description: "star",
+			sub:         SelectStar(From(Ref("bans"))),
+		},
+		{
+			description: "qualified star",
+			sub:         Select(Ref("b.*"), From(Ref("bans b"))),
+		},
+		{
+			description: "multiple columns",
+			sub:         Select(Columns(Ref("user_id"), Ref("reason")), From(Ref("bans"))),
+		},
+		{
+			description: "compound of stars",
+			sub:         Union(SelectStar(From(Ref("bans"))), SelectStar(From(Ref("mutes")))),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			st := Select(Ref("*"), From(Ref("users")), Where(NotInSafe(Ref("id"), c.sub)))
+
+			_, err := st.BuildChecked()
+			is.True(errors.Is(err, ErrInvalidClause))
+		})
+	}
+}
+
+func TestNotInSafeArgs(t *testing.T) {
+	is := is.New(t)
+
+	sub := Select(Ref("user_id"), From(Ref("bans")), Where(Equals(Ref("reason"), Arg("spam"))))
+	st := Select(Ref("*"), From(Ref("users")), Where(Equals(Ref("team"), Arg(1)), NotInSafe(Ref("users.id"), sub), Equals(Ref("active"), Arg(true))))
+
+	sql, args := st.BuildArgsWithDialect(Postgres)
+	is.Equal("select * from users where (team = $1 and not exists (select 1 from (select user_id as value from bans where (reason = $2)) as _not_in where (_not_in.value = users.id)) and active = $3)", sql)
+	is.Equal([]interface{}{1, "spam", true}, args)
+
+	// the subquery is left alone
+	is.Equal("select user_id from bans where (reason = ?)", sub.Build())
+}
@@ -0,0 +1,82 @@
+package sqlbuilder
+
+import (
+	"container/list"
+	"sync"
+)
+
+// StatementCache memoizes built SQL by key so statements that only differ in
+// their bound args aren't rebuilt every time. It's safe for concurrent use.
+// Once it holds more than its max size, the least recently used SQL is
+// evicted.
+type StatementCache struct {
+	mu      sync.RWMutex
+	size    int
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type cacheEntry struct {
+	key string
+	sql string
+}
+
+// NewStatementCache returns a StatementCache holding at most size statements.
+// A size of 0 or less doesn't limit the cache.
+func NewStatementCache(size int) *StatementCache {
+	return &StatementCache{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// Get returns the SQL cached for key, calling build and building the statement
+// it returns when key isn't cached yet. The statement must have the same
+// structure every time it's built for key, since only the first is kept. When
+// the statement recorded an error it's returned and nothing is cached.
+func (c *StatementCache) Get(key string, build func() Statement) (string, error) {
+	c.mu.Lock()
+	if el, ok := c.entries[key]; ok {
+		c.order.MoveToFront(el)
+		c.mu.Unlock()
+
+		return el.Value.(*cacheEntry).sql, nil
+	}
+	c.mu.Unlock()
+
+	// build outside of the lock so slow builds don't block other keys
+	st := build()
+	if err := st.Err(); err != nil {
+		return "", err
+	}
+
+	sql := st.Build()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[key]; ok {
+		c.order.MoveToFront(el)
+
+		return el.Value.(*cacheEntry).sql, nil
+	}
+
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, sql: sql})
+
+	if c.size > 0 && c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+
+	return sql, nil
+}
+
+// Len returns the number of statements in the cache.
+func (c *StatementCache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.order.Len()
+}
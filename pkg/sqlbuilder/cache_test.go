@@ -0,0 +1,94 @@
+package sqlbuilder
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestStatementCache(t *testing.T) {
+	is := is.New(t)
+	cache := NewStatementCache(2)
+
+	builds := 0
+	byID := func() Statement {
+		builds++
+		return Select(Ref("*"), From(Ref("users")), Where(Equals(Ref("id"), Placeholder())))
+	}
+
+	sql, err := cache.Get("by-id", byID)
+	is.NoErr(err)
+	is.Equal("select * from users where (id = ?)", sql)
+
+	sql, err = cache.Get("by-id", byID)
+	is.NoErr(err)
+	is.Equal("select * from users where (id = ?)", sql)
+	is.Equal(1, builds)
+
+	cache.Get("a", func() Statement { return SelectStar(From(Ref("a"))) })
+	cache.Get("by-id", byID)
+	cache.Get("b", func() Statement { return SelectStar(From(Ref("b"))) })
+
+	// "a" was least recently used so it's evicted before "by-id"
+	is.Equal(2, cache.Len())
+	is.Equal(1, builds)
+
+	cache.Get("by-id", byID)
+	is.Equal(1, builds)
+
+	rebuilt := false
+	cache.Get("a", func() Statement {
+		rebuilt = true
+		return SelectStar(From(Ref("a")))
+	})
+	is.True(rebuilt)
+}
+
+func TestStatementCacheErr(t *testing.T) {
+	is := is.New(t)
+	cache := NewStatementCache(2)
+
+	sql, err := cache.Get("bad", func() Statement { return SelectStar(From(Ref("t")), Where(nil)) })
+	is.True(errors.Is(err, ErrNilExpression))
+	is.Equal("", sql)
+	is.Equal(0, cache.Len())
+}
+
+func TestStatementCacheUnbounded(t *testing.T) {
+	is := is.New(t)
+	cache := NewStatementCache(0)
+
+	for i := 0; i < 100; i++ {
+		cache.Get(strconv.Itoa(i), func() Statement { return SelectStar(From(Ref("t"))) })
+	}
+
+	is.Equal(100, cache.Len())
+}
+
+func TestStatementCacheConcurrent(t *testing.T) {
+	is := is.New(t)
+	cache := NewStatementCache(8)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			for j := 0; j < 100; j++ {
+				key := strconv.Itoa((i + j) % 12)
+				sql, err := cache.Get(key, func() Statement { return SelectStar(From(Ref("t" + key))) })
+				is.NoErr(err)
+				is.Equal("select * from t"+key, sql)
+			}
+		}(i)
+	}
+
+	wg.Wait()
+	is.Equal(8, cache.Len())
+}
@@ -0,0 +1,106 @@
+package sqlbuilder
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CaseExpression builds a sql case expression. Create one with Case and add
+// branches with When and Else.
+type CaseExpression struct {
+	operand Expression
+	whens   []caseWhen
+	els     Expression
+}
+
+type caseWhen struct {
+	condition Expression
+	result    Expression
+}
+
+// Case starts a case expression. Without an operand it's a searched case where
+// each When takes a predicate. With an operand it's a simple case where each
+// When takes a value that's compared to the operand:
+//
+//	Case().When(Greater(Ref("n"), Placeholder()), Const("big"))
+//	Case(Ref("kind")).When(Const("a"), Const("apple"))
+//
+// Only the first operand is used. A case needs at least one When; without one
+// the statement it's used in records an error wrapping ErrEmptyClause.
+func Case(operand ...Expression) CaseExpression {
+	var c CaseExpression
+
+	if len(operand) > 0 {
+		c.operand = operand[0]
+	}
+
+	return c
+}
+
+// When adds a "when condition then result" branch. It returns a new
+// CaseExpression so a partially built case can be reused safely.
+func (c CaseExpression) When(condition, result Expression) CaseExpression {
+	whens := make([]caseWhen, len(c.whens), len(c.whens)+1)
+	copy(whens, c.whens)
+	c.whens = append(whens, caseWhen{condition: condition, result: result})
+
+	return c
+}
+
+// Else sets the result used when no branch matches.
+func (c CaseExpression) Else(result Expression) CaseExpression {
+	c.els = result
+
+	return c
+}
+
+func (c CaseExpression) expressionErr() error {
+	if len(c.whens) == 0 {
+		return fmt.Errorf("%w: case has no when branches", ErrEmptyClause)
+	}
+
+	return nil
+}
+
+func (c CaseExpression) Build() string { return c.BuildWith(&BuildContext{}) }
+
+func (c CaseExpression) BuildWith(ctx *BuildContext) string {
+	builder := strings.Builder{}
+	builder.WriteString("case ")
+
+	if c.operand != nil {
+		builder.WriteString(ctx.Build(c.operand) + " ")
+	}
+
+	for _, w := range c.whens {
+		builder.WriteString("when " + ctx.Build(w.condition) + " then " + ctx.Build(w.result) + " ")
+	}
+
+	if c.els != nil {
+		builder.WriteString("else " + ctx.Build(c.els) + " ")
+	}
+
+	builder.WriteString("end")
+
+	return builder.String()
+}
+
+// Coalesce builds "coalesce(a, b, ...)", the first of exprs that isn't null.
+func Coalesce(exprs ...Expression) Expression {
+	return Func("coalesce", exprs...)
+}
+
+// NullIf builds "nullif(a, b)", which is null when a equals b and a otherwise.
+func NullIf(a, b Expression) Expression {
+	return Func("nullif", a, b)
+}
+
+// Greatest builds "greatest(a, b, ...)", the largest of exprs.
+func Greatest(exprs ...Expression) Expression {
+	return Func("greatest", exprs...)
+}
+
+// Least builds "least(a, b, ...)", the smallest of exprs.
+func Least(exprs ...Expression) Expression {
+	return Func("least", exprs...)
+}
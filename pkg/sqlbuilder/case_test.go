@@ -0,0 +1,125 @@
+package sqlbuilder
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestCase(t *testing.T) {
+	cases := []struct {
+		description string
+		expected    string
+		args        []interface{}
+		statement   Statement
+	}{
+		{
+			description: "searched case as a column",
+			expected:    "select id, case when score > ? then 'high' when score > ? then 'mid' else 'low' end as bucket from items",
+			args:        []interface{}{90, 50},
+			statement: Select(
+				Columns(
+					Ref("id"),
+					As(Case().
+						When(Greater(Ref("score"), Arg(90)), Const("high")).
+						When(Greater(Ref("score"), Arg(50)), Const("mid")).
+						Else(Const("low")), "bucket"),
+				),
+				From(Ref("items")),
+			),
+		},
+		{
+			description: "simple case in where",
+			expected:    "select * from items where (case kind when 'a' then ? else ? end = ?)",
+			args:        []interface{}{1, 2, 1},
+			statement: Select(
+				Ref("*"),
+				From(Ref("items")),
+				Where(Equals(
+					Case(Ref("kind")).When(Const("a"), Arg(1)).Else(Arg(2)),
+					Arg(1),
+				)),
+			),
+		},
+		{
+			description: "case without else",
+			expected:    "select case when deleted then 'gone' end from items",
+			statement: Select(
+				Case().When(Ref("deleted"), Const("gone")),
+				From(Ref("items")),
+			),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			sql, args := c.statement.BuildArgs()
+			is.Equal(c.expected, sql)
+			is.Equal(c.args, args)
+		})
+	}
+}
+
+func TestCaseReuse(t *testing.T) {
+	is := is.New(t)
+
+	base := Case().When(Ref("a"), Const("a"))
+	b := base.When(Ref("b"), Const("b"))
+	c := base.When(Ref("c"), Const("c"))
+
+	is.Equal("case when a then 'a' when b then 'b' end", b.Build())
+	is.Equal("case when a then 'a' when c then 'c' end", c.Build())
+	is.Equal("case when a then 'a' end", base.Build())
+}
+
+func TestCaseWithoutWhen(t *testing.T) {
+	is := is.New(t)
+
+	st := Select(As(Case(Ref("kind")).Else(Const("other")), "label"), From(Ref("items")))
+
+	is.True(errors.Is(st.Err(), ErrEmptyClause))
+	is.Equal("", st.Build())
+}
+
+func TestConditionalFuncs(t *testing.T) {
+	cases := []struct {
+		description string
+		expected    string
+		expr        Expression
+	}{
+		{
+			description: "coalesce",
+			expected:    "coalesce(title, 'no title') as title",
+			expr:        As(Coalesce(Ref("title"), Const("no title")), "title"),
+		},
+		{
+			description: "coalesce many",
+			expected:    "coalesce(nick, name, 'anon') as display",
+			expr:        As(Coalesce(Ref("nick"), Ref("name"), Const("anon")), "display"),
+		},
+		{
+			description: "nullif",
+			expected:    "nullif(name, '')",
+			expr:        NullIf(Ref("name"), Const("")),
+		},
+		{
+			description: "greatest",
+			expected:    "greatest(a, b, ?)",
+			expr:        Greatest(Ref("a"), Ref("b"), Placeholder()),
+		},
+		{
+			description: "least",
+			expected:    "least(updated_at, now())",
+			expr:        Least(Ref("updated_at"), Func("now")),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			is.Equal(c.expected, c.expr.Build())
+		})
+	}
+}
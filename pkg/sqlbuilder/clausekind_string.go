@@ -9,17 +9,38 @@ func _() {
 	// Re-run the stringer command to generate them again.
 	var x [1]struct{}
 	_ = x[_unknownClause-0]
-	_ = x[_FromClause-1]
-	_ = x[_JoinClause-2]
-	_ = x[_LeftJoinClause-3]
-	_ = x[_WhereClause-4]
-	_ = x[_GroupByClause-5]
-	_ = x[_OrderByClause-6]
+	_ = x[_InsertColumnsClause-1]
+	_ = x[_ValuesClause-2]
+	_ = x[_DefaultValuesClause-3]
+	_ = x[_OnConflictClause-4]
+	_ = x[_FromClause-5]
+	_ = x[_JoinClause-6]
+	_ = x[_LeftJoinClause-7]
+	_ = x[_InnerJoinClause-8]
+	_ = x[_RightJoinClause-9]
+	_ = x[_FullJoinClause-10]
+	_ = x[_CrossJoinClause-11]
+	_ = x[_SetClause-12]
+	_ = x[_UpdateFromClause-13]
+	_ = x[_UsingClause-14]
+	_ = x[_WhereClause-15]
+	_ = x[_GroupByClause-16]
+	_ = x[_HavingClause-17]
+	_ = x[_WindowClause-18]
+	_ = x[_OrderByClause-19]
+	_ = x[_LimitClause-20]
+	_ = x[_OffsetClause-21]
+	_ = x[_FetchClause-22]
+	_ = x[_ReturningClause-23]
+	_ = x[_LockingClause-24]
+	_ = x[_RestartIdentityClause-25]
+	_ = x[_CascadeClause-26]
+	_ = x[_CommentClause-27]
 }
 
-const _ClauseKind_name = "_unknownClausefromjoinleft joinwheregroup byorder by"
+const _ClauseKind_name = "_unknownClausecolumnsvaluesdefault valueson conflictfromjoinleft joininner joinright joinfull joincross joinsetfromusingwheregroup byhavingwindoworder bylimitoffsetfetchreturninglockingrestart identitycascadecomment"
 
-var _ClauseKind_index = [...]uint8{0, 14, 18, 22, 31, 36, 44, 52}
+var _ClauseKind_index = [...]uint8{0, 14, 21, 27, 41, 52, 56, 60, 69, 79, 89, 98, 108, 111, 115, 120, 125, 133, 139, 145, 153, 158, 164, 169, 178, 185, 201, 208, 215}
 
 func (i ClauseKind) String() string {
 	if i >= ClauseKind(len(_ClauseKind_index)-1) {
@@ -0,0 +1,30 @@
+package sqlbuilder_test
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+
+	"go.e64ec.com/tools/pkg/sqlbuilder"
+)
+
+func TestClausesOfKind(t *testing.T) {
+	is := is.New(t)
+
+	st := sqlbuilder.Select(sqlbuilder.Ref("*"), sqlbuilder.From(sqlbuilder.Ref("items")),
+		sqlbuilder.Where(sqlbuilder.Equals(sqlbuilder.Ref("a"), sqlbuilder.Placeholder())),
+		sqlbuilder.Join(sqlbuilder.Ref("tags"), sqlbuilder.Equals(sqlbuilder.Ref("tags.item_id"), sqlbuilder.Ref("items.id"))),
+		sqlbuilder.Where(sqlbuilder.Equals(sqlbuilder.Ref("b"), sqlbuilder.Placeholder())),
+		sqlbuilder.Limit(10),
+	)
+
+	wheres := st.ClausesOfKind(sqlbuilder.WhereClauseKind)
+	is.Equal(2, len(wheres))
+	is.Equal("(a = ?)", wheres[0].Build())
+	is.Equal("(b = ?)", wheres[1].Build())
+	is.Equal(sqlbuilder.WhereClauseKind, wheres[0].Kind())
+
+	is.Equal(1, len(st.ClausesOfKind(sqlbuilder.JoinClauseKind)))
+	is.Equal(1, len(st.ClausesOfKind(sqlbuilder.LimitClauseKind)))
+	is.Equal(0, len(st.ClausesOfKind(sqlbuilder.OrderByClauseKind)))
+}
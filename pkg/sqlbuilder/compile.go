@@ -0,0 +1,90 @@
+package sqlbuilder
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrArgCount is returned when the number of arguments given for a compiled
+// statement doesn't match its number of unbound placeholders.
+var ErrArgCount = errors.New("sqlbuilder: wrong number of arguments")
+
+// CompiledStatement is a statement that has been built once so its SQL can be
+// reused with different arguments, for example with a *sql.Stmt. It's
+// immutable and safe to use from multiple goroutines.
+type CompiledStatement struct {
+	sql    string
+	params []param
+	err    error
+}
+
+// Compile builds the statement once and returns the result as a
+// CompiledStatement. A statement that recorded an error isn't built; its
+// error is returned by Err and Args instead.
+func (s Statement) Compile() CompiledStatement {
+	return s.CompileWithDialect(nil)
+}
+
+// CompileWithDialect is like Compile but builds the statement for d.
+func (s Statement) CompileWithDialect(d Dialect) CompiledStatement {
+	if s.err != nil {
+		return CompiledStatement{err: s.err}
+	}
+
+	ctx := NewBuildContext(d)
+	sql := s.BuildWith(ctx)
+
+	return CompiledStatement{sql: sql, params: ctx.params}
+}
+
+// SQL returns the built statement.
+func (c CompiledStatement) SQL() string {
+	return c.sql
+}
+
+// Err returns the error recorded by the statement that was compiled.
+func (c CompiledStatement) Err() error {
+	return c.err
+}
+
+// NumArgs returns the number of arguments Args expects, which is the number of
+// placeholders that weren't built with Arg.
+func (c CompiledStatement) NumArgs() int {
+	n := 0
+
+	for _, p := range c.params {
+		if !p.bound {
+			n++
+		}
+	}
+
+	return n
+}
+
+// Args returns the arguments for every placeholder in the statement, in
+// order. Placeholders built with Arg keep their bound value and the rest are
+// filled in from args, in order. An error wrapping ErrArgCount is returned if
+// len(args) doesn't match NumArgs, and Err when it isn't nil.
+func (c CompiledStatement) Args(args ...interface{}) ([]interface{}, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	if n := c.NumArgs(); len(args) != n {
+		return nil, fmt.Errorf("%w: got %d, expected %d", ErrArgCount, len(args), n)
+	}
+
+	values := make([]interface{}, len(c.params))
+
+	for i, p := range c.params {
+		if p.bound {
+			values[i] = p.value
+			continue
+		}
+
+		values[i] = args[0]
+		args = args[1:]
+	}
+
+	return values, nil
+}
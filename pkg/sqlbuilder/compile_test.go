@@ -0,0 +1,82 @@
+package sqlbuilder
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestCompile(t *testing.T) {
+	is := is.New(t)
+
+	st := Select(
+		Ref("*"),
+		From(Ref("items")),
+		Where(
+			Equals(Ref("owner"), Placeholder()),
+			Equals(Ref("deleted"), Arg(false)),
+			Greater(Ref("id"), Placeholder()),
+		),
+	)
+
+	c := st.Compile()
+	is.Equal("select * from items where (owner = ? and deleted = ? and id > ?)", c.SQL())
+	is.Equal(2, c.NumArgs())
+
+	args, err := c.Args("kyle", 10)
+	is.NoErr(err)
+	is.Equal([]interface{}{"kyle", false, 10}, args)
+
+	args, err = c.Args("someone", 20)
+	is.NoErr(err)
+	is.Equal([]interface{}{"someone", false, 20}, args)
+
+	_, err = c.Args("kyle")
+	is.True(errors.Is(err, ErrArgCount))
+}
+
+func TestCompileErr(t *testing.T) {
+	is := is.New(t)
+
+	c := Select(Ref("*"), From(Ref("items")), Where(nil)).Compile()
+	is.True(errors.Is(c.Err(), ErrNilExpression))
+	is.Equal("", c.SQL())
+
+	_, err := c.Args()
+	is.True(errors.Is(err, ErrNilExpression))
+}
+
+func TestCompileWithDialect(t *testing.T) {
+	is := is.New(t)
+
+	c := Select(Ref("*"), From(Ref("items")), Where(Equals(Ref("id"), Placeholder()))).CompileWithDialect(Postgres)
+
+	is.Equal("select * from items where (id = $1)", c.SQL())
+
+	args, err := c.Args(1)
+	is.NoErr(err)
+	is.Equal([]interface{}{1}, args)
+}
+
+func TestCompileConcurrent(t *testing.T) {
+	is := is.New(t)
+
+	c := Select(Ref("*"), From(Ref("items")), Where(Equals(Ref("id"), Placeholder()))).Compile()
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			args, err := c.Args(i)
+			is.NoErr(err)
+			is.Equal([]interface{}{i}, args)
+		}(i)
+	}
+
+	wg.Wait()
+}
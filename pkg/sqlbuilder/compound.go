@@ -0,0 +1,69 @@
+package sqlbuilder
+
+import "fmt"
+
+// compoundOperators maps the operators accepted by Compound to their kind.
+var compoundOperators = map[string]StatementKind{
+	"union":         _UnionStatement,
+	"union all":     _UnionAllStatement,
+	"intersect":     _IntersectStatement,
+	"intersect all": _IntersectAllStatement,
+	"except":        _ExceptStatement,
+	"except all":    _ExceptAllStatement,
+}
+
+// CompoundStatement is a compound statement with methods to sort and limit the
+// combined result. The embedded Statement can be built or used as an operand
+// of another compound.
+type CompoundStatement struct {
+	Statement
+}
+
+// Compound combines a and b with op, which is one of "union", "union all",
+// "intersect", "intersect all", "except" or "except all". Each operand is
+// wrapped in "()" so nested compounds keep their grouping:
+//
+//	Compound("union", a, b).OrderBy("id").Limit(10)
+//
+// builds "(a) union (b) order by id limit 10". Compound panics on any other
+// op.
+func Compound(op string, a, b Statement) CompoundStatement {
+	kind, ok := compoundOperators[op]
+	if !ok {
+		panic(fmt.Sprintf("sqlbuilder: invalid compound operator %q", op))
+	}
+
+	return CompoundStatement{compound(kind, a, b, nil)}
+}
+
+// Apply returns a copy of c with opts applied to the compound as a whole.
+func (c CompoundStatement) Apply(opts ...StatementOption) CompoundStatement {
+	st := c.Statement
+	st.Clauses = append([]Clause(nil), st.Clauses...)
+
+	for _, opt := range opts {
+		opt(&st)
+	}
+
+	return CompoundStatement{st}
+}
+
+// OrderBy sorts the combined result by cols.
+func (c CompoundStatement) OrderBy(cols ...string) CompoundStatement {
+	return c.Apply(OrderBy(cols...))
+}
+
+// OrderByExpr sorts the combined result by cols.
+func (c CompoundStatement) OrderByExpr(cols ...Expression) CompoundStatement {
+	return c.Apply(OrderByExpr(cols...))
+}
+
+// Limit limits the combined result to n rows.
+func (c CompoundStatement) Limit(n int) CompoundStatement {
+	return c.Apply(Limit(n))
+}
+
+// Offset skips the first n rows of the combined result.
+func (c CompoundStatement) Offset(n int) CompoundStatement {
+	return c.Apply(Offset(n))
+}
@@ -0,0 +1,87 @@
+package sqlbuilder
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestCompoundOperators(t *testing.T) {
+	a := Select(Ref("id"), From(Ref("a")), Where(Equals(Ref("x"), Arg(1))))
+	b := Select(Ref("id"), From(Ref("b")), Where(Equals(Ref("x"), Arg(2))))
+	c := Select(Ref("id"), From(Ref("c")), Where(Equals(Ref("x"), Arg(3))))
+
+	cases := []struct {
+		description string
+		expected    string
+		args        []interface{}
+		statement   Statement
+	}{
+		{
+			description: "intersect all",
+			expected:    "(select id from a where (x = ?)) intersect all (select id from b where (x = ?))",
+			args:        []interface{}{1, 2},
+			statement:   IntersectAll(a, b),
+		},
+		{
+			description: "except all",
+			expected:    "(select id from a where (x = ?)) except all (select id from b where (x = ?))",
+			args:        []interface{}{1, 2},
+			statement:   ExceptAll(a, b),
+		},
+		{
+			description: "compound order by and limit",
+			expected:    "(select id from a where (x = ?)) union all (select id from b where (x = ?)) order by id desc limit 10 offset 5",
+			args:        []interface{}{1, 2},
+			statement:   Compound("union all", a, b).OrderByExpr(Desc(Ref("id"))).Limit(10).Offset(5).Statement,
+		},
+		{
+			description: "nested compounds keep precedence",
+			expected:    "((select id from a where (x = ?)) intersect (select id from b where (x = ?))) union (select id from c where (x = ?)) order by id",
+			args:        []interface{}{1, 2, 3},
+			statement:   Compound("union", Compound("intersect", a, b).Statement, c).OrderBy("id").Statement,
+		},
+		{
+			description: "except flattens its first operand",
+			expected:    "(select id from a where (x = ?)) except (select id from b where (x = ?)) except (select id from c where (x = ?))",
+			args:        []interface{}{1, 2, 3},
+			statement:   Except(Except(a, b), c),
+		},
+		{
+			description: "except keeps its second operand grouped",
+			expected:    "(select id from a where (x = ?)) except ((select id from b where (x = ?)) except (select id from c where (x = ?)))",
+			args:        []interface{}{1, 2, 3},
+			statement:   Except(a, Except(b, c)),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			sql, args := c.statement.BuildArgs()
+			is.Equal(c.expected, sql)
+			is.Equal(c.args, args)
+			is.NoErr(c.statement.Validate())
+		})
+	}
+}
+
+func TestCompoundApplyCopies(t *testing.T) {
+	is := is.New(t)
+
+	base := Compound("union", Select(Ref("1")), Select(Ref("2"))).OrderBy("1")
+	limited := base.Limit(1)
+
+	is.Equal("(select 1) union (select 2) order by 1", base.Build())
+	is.Equal("(select 1) union (select 2) order by 1 limit 1", limited.Build())
+}
+
+func TestCompoundInvalidOperator(t *testing.T) {
+	is := is.New(t)
+
+	defer func() {
+		is.True(recover() != nil)
+	}()
+
+	Compound("minus", Select(Ref("1")), Select(Ref("2")))
+}
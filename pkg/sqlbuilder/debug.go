@@ -0,0 +1,49 @@
+package sqlbuilder
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// RenderDebug builds the statement with every value bound by Arg or NamedArg
+// written inline in place of its placeholder, so it can be pasted into a
+// database shell. Strings are quoted, numbers are left bare and nil is
+// "null". Placeholders without a bound value are left as they are.
+//
+// RenderDebug is for logging and debugging only. The values aren't escaped
+// well enough to be safe, so the result must never be executed.
+func (s Statement) RenderDebug() string {
+	return s.BuildWith(&BuildContext{debug: true})
+}
+
+// renderValue writes value as a literal for d.
+func renderValue(d Dialect, value interface{}) string {
+	if v, ok := value.(driver.Valuer); ok {
+		if dv, err := v.Value(); err == nil {
+			value = dv
+		}
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(v)
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprint(v)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case []byte:
+		return d.QuoteString(string(v))
+	case time.Time:
+		return d.QuoteString(v.Format(time.RFC3339Nano))
+	case fmt.Stringer:
+		return d.QuoteString(v.String())
+	}
+
+	return d.QuoteString(fmt.Sprint(value))
+}
@@ -0,0 +1,77 @@
+package sqlbuilder
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestRenderDebug(t *testing.T) {
+	cases := []struct {
+		description string
+		expected    string
+		statement   Statement
+	}{
+		{
+			description: "values",
+			expected:    "select * from users where (id = 7 and name = 'o''brien' and score > 1.5 and active = true and deleted_at = null)",
+			statement: Select(Ref("*"), From(Ref("users")), Where(
+				Equals(Ref("id"), Arg(7)),
+				Equals(Ref("name"), Arg("o'brien")),
+				Greater(Ref("score"), Arg(1.5)),
+				Equals(Ref("active"), Arg(true)),
+				Equals(Ref("deleted_at"), Arg(nil)),
+			)),
+		},
+		{
+			description: "time and valuer",
+			expected:    "update users set seen_at = '2020-01-02T03:04:05Z', nick = null, team = 'a' where (id = 1)",
+			statement: Update(Ref("users"),
+				Set(
+					Equals(Ref("seen_at"), Arg(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC))),
+					Equals(Ref("nick"), Arg(sql.NullString{})),
+					Equals(Ref("team"), Arg(sql.NullString{String: "a", Valid: true})),
+				),
+				Where(Equals(Ref("id"), Arg(1))),
+			),
+		},
+		{
+			description: "bare placeholders",
+			expected:    "select * from users where (id = 1 and team = ?)",
+			statement:   Select(Ref("*"), From(Ref("users")), Where(Equals(Ref("id"), Arg(1)), Equals(Ref("team"), Placeholder()))),
+		},
+		{
+			description: "named args",
+			expected:    "select * from users where (id = 1 and name = 'kyle')",
+			statement: Select(Ref("*"), From(Ref("users")), Where(
+				Equals(Ref("id"), NamedArg("id", 1)),
+				Equals(Ref("name"), NamedArg("name", "kyle")),
+			)),
+		},
+		{
+			description: "bytes",
+			expected:    "insert into files (data) values ('abc')",
+			statement:   Insert(Ref("files"), InsertColumns("data"), Values(Arg([]byte("abc")))),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			is.Equal(c.expected, c.statement.RenderDebug())
+		})
+	}
+}
+
+func TestRenderDebugLeavesBuildAlone(t *testing.T) {
+	is := is.New(t)
+
+	st := Select(Ref("*"), From(Ref("users")), Where(Equals(Ref("id"), Arg(7))))
+	st.RenderDebug()
+
+	sql, args := st.BuildArgs()
+	is.Equal("select * from users where (id = ?)", sql)
+	is.Equal([]interface{}{7}, args)
+}
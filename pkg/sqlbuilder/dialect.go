@@ -0,0 +1,316 @@
+package sqlbuilder
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Dialect controls the parts of a statement that differ between databases.
+type Dialect interface {
+	// Placeholder returns the placeholder for the nth parameter in a
+	// statement, counting from 1 in the order placeholders appear.
+	Placeholder(n int) string
+
+	// QuoteIdentifier quotes name so it can be used as an identifier, such
+	// as an alias, even if it contains characters that aren't otherwise
+	// allowed.
+	QuoteIdentifier(name string) string
+
+	// QuoteString quotes value as a string literal, escaping anything in it
+	// that would otherwise end the literal early.
+	QuoteString(value string) string
+
+	// Concat concatenates already built string expressions.
+	Concat(values []string) string
+
+	// Supports reports whether the dialect supports f. Statements fall back
+	// to a portable form, or leave the feature out, when it isn't supported.
+	Supports(f Feature) bool
+}
+
+// Feature is a piece of SQL that only some dialects support.
+type Feature uint
+
+const (
+	// FeatureDistinctOn is "select distinct on (...)".
+	FeatureDistinctOn Feature = iota + 1
+
+	// FeatureOnDuplicateKeyUpdate is "on duplicate key update" in place of
+	// "on conflict (...) do update set".
+	FeatureOnDuplicateKeyUpdate
+
+	// FeatureILike is the case insensitive "ilike" operator.
+	FeatureILike
+
+	// FeatureRegexpLike is the "regexp_like(value, pattern, flags)" function
+	// in place of the "~" family of regex operators.
+	FeatureRegexpLike
+
+	// FeatureHashBitXor is "#" as the bitwise xor operator, for dialects
+	// where "^" is exponentiation.
+	FeatureHashBitXor
+
+	// FeatureOffsetFetch is "offset n rows fetch next m rows only" in place
+	// of "limit m offset n". Statements without an order by are built with
+	// "order by (select null)" since the offset needs one.
+	FeatureOffsetFetch
+
+	// FeatureTableStatement is "table t" as shorthand for "select * from t".
+	FeatureTableStatement
+)
+
+// SQLite is the Dialect for SQLite. It uses "?" placeholders and only quotes
+// identifiers that need it. It's the default dialect unless it's changed with
+// SetDefaultDialect.
+var SQLite Dialect = sqliteDialect{}
+
+// Postgres is the Dialect for PostgreSQL. It uses numbered placeholders ($1,
+// $2, ...).
+var Postgres Dialect = postgresDialect{}
+
+// SQLServer is the Dialect for Microsoft SQL Server. It uses numbered
+// placeholders (@p1, @p2, ...), quotes identifiers with brackets and paginates
+// with offset and fetch.
+var SQLServer Dialect = sqlserverDialect{}
+
+// PostgresMaxPlaceholders is the most placeholders a Postgres statement can
+// have.
+const PostgresMaxPlaceholders = 65535
+
+// MySQL is the Dialect for MySQL. It quotes identifiers with backticks,
+// escapes backslashes in strings and concatenates with the concat function.
+var MySQL Dialect = mysqlDialect{}
+
+type sqliteDialect struct{}
+
+func (sqliteDialect) Placeholder(n int) string           { return defaultPlaceholder }
+func (sqliteDialect) QuoteIdentifier(name string) string { return quoteIdentifier(name, `"`) }
+func (sqliteDialect) QuoteString(value string) string    { return quoteString(value) }
+func (sqliteDialect) Concat(values []string) string      { return concat(values) }
+func (sqliteDialect) Supports(f Feature) bool            { return false }
+
+type postgresDialect struct{}
+
+func (postgresDialect) Placeholder(n int) string           { return "$" + strconv.Itoa(n) }
+func (postgresDialect) QuoteIdentifier(name string) string { return quoteIdentifier(name, `"`) }
+func (postgresDialect) QuoteString(value string) string    { return quoteString(value) }
+func (postgresDialect) Concat(values []string) string      { return concat(values) }
+
+func (postgresDialect) Supports(f Feature) bool {
+	switch f {
+	case FeatureDistinctOn, FeatureILike, FeatureHashBitXor, FeatureTableStatement:
+		return true
+	}
+
+	return false
+}
+
+type mysqlDialect struct{}
+
+func (mysqlDialect) Placeholder(n int) string           { return defaultPlaceholder }
+func (mysqlDialect) QuoteIdentifier(name string) string { return quoteIdentifier(name, "`") }
+
+func (mysqlDialect) Supports(f Feature) bool {
+	switch f {
+	case FeatureOnDuplicateKeyUpdate, FeatureRegexpLike:
+		return true
+	}
+
+	return false
+}
+
+// QuoteString escapes backslashes as well as single quotes, since mysql treats
+// backslashes in string literals as escape characters by default.
+func (mysqlDialect) QuoteString(value string) string {
+	return quoteString(strings.ReplaceAll(value, `\`, `\\`))
+}
+
+func (mysqlDialect) Concat(values []string) string {
+	return "concat(" + strings.Join(values, defaultExpressionDelimeter) + ")"
+}
+
+var dialects = struct {
+	sync.RWMutex
+	byName map[string]Dialect
+	def    Dialect
+}{
+	byName: map[string]Dialect{
+		"sqlite":    SQLite,
+		"postgres":  Postgres,
+		"mysql":     MySQL,
+		"sqlserver": SQLServer,
+	},
+	def: SQLite,
+}
+
+// RegisterDialect makes d available by name to LookupDialect. The built-in
+// dialects are registered as "sqlite", "postgres", "mysql" and "sqlserver".
+// Registering a name again replaces the dialect registered with it.
+func RegisterDialect(name string, d Dialect) {
+	dialects.Lock()
+	defer dialects.Unlock()
+
+	dialects.byName[name] = d
+}
+
+// LookupDialect returns the dialect registered with name.
+func LookupDialect(name string) (Dialect, bool) {
+	dialects.RLock()
+	defer dialects.RUnlock()
+
+	d, ok := dialects.byName[name]
+
+	return d, ok
+}
+
+// SetDefaultDialect sets the dialect used by Build, BuildArgs and every other
+// build without a dialect of its own. A nil d resets the default to SQLite.
+// It's meant to be called once while the program starts.
+func SetDefaultDialect(d Dialect) {
+	if d == nil {
+		d = SQLite
+	}
+
+	dialects.Lock()
+	defer dialects.Unlock()
+
+	dialects.def = d
+}
+
+// DefaultDialect returns the dialect set with SetDefaultDialect.
+func DefaultDialect() Dialect {
+	dialects.RLock()
+	defer dialects.RUnlock()
+
+	return dialects.def
+}
+
+type sqlserverDialect struct{}
+
+func (sqlserverDialect) Placeholder(n int) string        { return "@p" + strconv.Itoa(n) }
+func (sqlserverDialect) QuoteString(value string) string { return quoteString(value) }
+
+// QuoteIdentifier wraps name in brackets, doubling any closing brackets
+// already in name.
+func (sqlserverDialect) QuoteIdentifier(name string) string {
+	return "[" + strings.ReplaceAll(name, "]", "]]") + "]"
+}
+
+func (sqlserverDialect) Concat(values []string) string {
+	return "concat(" + strings.Join(values, defaultExpressionDelimeter) + ")"
+}
+
+func (sqlserverDialect) Supports(f Feature) bool {
+	switch f {
+	case FeatureOffsetFetch:
+		return true
+	}
+
+	return false
+}
+
+// concat joins values with the standard sql concatenation operator.
+func concat(values []string) string {
+	return strings.Join(values, " || ")
+}
+
+// quoteIdentifier wraps name in q, doubling any q already in name.
+func quoteIdentifier(name, q string) string {
+	return q + strings.ReplaceAll(name, q, q+q) + q
+}
+
+// quoteString wraps value in single quotes, doubling any single quotes already
+// in value. This is the standard SQL way of escaping string literals.
+func quoteString(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// quoteAlias returns name as it is when it's a plain lowercase identifier that
+// isn't a reserved word in d, and quoted for d otherwise. Unquoted names are
+// folded to lowercase by postgres, so names with uppercase letters are quoted
+// to keep their case.
+func quoteAlias(d Dialect, name string) string {
+	if isIdentifier(name) && strings.ToLower(name) == name && !isReserved(d, name) {
+		return name
+	}
+
+	return d.QuoteIdentifier(name)
+}
+
+// reservedWords are reserved in every dialect and can't be used as an alias
+// without quoting them.
+var reservedWords = wordSet(`all and any as asc between by case check collate column constraint
+	create cross default delete desc distinct drop else end except exists false foreign from
+	full group having in inner insert intersect into is join left like not null on or order
+	primary references right select set table then to true union unique update using values
+	when where with`)
+
+// The words reserved in the built in dialects on top of reservedWords.
+var (
+	postgresReservedWords = wordSet(`analyse analyze array asymmetric both cast current_catalog current_date
+		current_role current_schema current_time current_timestamp current_user deferrable do
+		fetch for grant initially lateral leading limit localtime localtimestamp offset only
+		placing returning session_user some symmetric trailing user variadic window`)
+	mysqlReservedWords = wordSet(`accessible cast condition cube current_date current_time current_timestamp
+		current_user databases dense_rank div dual fetch first_value for force grant groups index
+		interval key keys kill lag last_value lateral lead limit lines load lock match mod
+		natural over partition rank range read recursive regexp rename repeat require row
+		row_number rows schema show signal usage window write xor`)
+	sqlserverReservedWords = wordSet(`backup browse bulk cascade close compute contains current_date
+		current_time current_timestamp current_user cursor database deny dump escape exec
+		execute file fillfactor for function grant identity index key kill lineno merge national
+		nocheck off offsets open option over percent pivot plan precision print proc procedure
+		public raiserror read restore revert rule save schema session_user some statistics
+		system_user top tran transaction trigger truncate unpivot user view waitfor`)
+)
+
+func wordSet(words string) map[string]bool {
+	set := make(map[string]bool)
+
+	for _, word := range strings.Fields(words) {
+		set[word] = true
+	}
+
+	return set
+}
+
+// isReserved reports whether word is a reserved word in d. Dialects other than
+// the built in ones only have reservedWords reserved.
+func isReserved(d Dialect, word string) bool {
+	word = strings.ToLower(word)
+
+	if reservedWords[word] {
+		return true
+	}
+
+	switch d.(type) {
+	case postgresDialect:
+		return postgresReservedWords[word]
+	case mysqlDialect:
+		return mysqlReservedWords[word]
+	case sqlserverDialect:
+		return sqlserverReservedWords[word]
+	}
+
+	return false
+}
+
+// isIdentifier reports whether name can be used as an identifier without
+// quoting it.
+func isIdentifier(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for i, r := range name {
+		switch {
+		case r == '_', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case r >= '0' && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,514 @@
+package sqlbuilder
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestPostgresPlaceholders(t *testing.T) {
+	cases := []struct {
+		description string
+		expected    string
+		statement   Statement
+	}{
+		{
+			description: "select with subselect and join",
+			expected:    "select * from (select id from items where (owner = $1)) join tags as t on t.name = $2 where (id > $3 and id < $4)",
+			statement: Select(
+				Ref("*"),
+				FromSubselect(Select(
+					Ref("id"),
+					From(Ref("items")),
+					Where(Equals(Ref("owner"), Placeholder())),
+				), ""),
+				Join(RefAs("tags", "t"), Equals(Ref("t.name"), Arg("go"))),
+				Where(Greater(Ref("id"), Placeholder()), Less(Ref("id"), Arg(10))),
+			),
+		},
+		{
+			description: "set, where, and limit",
+			expected:    "update items set name = $1, title = $2 where (id = $3) limit $4",
+			statement: Update(
+				Ref("items"),
+				LimitP(),
+				Where(Equals(Ref("id"), Placeholder())),
+				Set(
+					Equals(Ref("name"), Placeholder()),
+					Equals(Ref("title"), Arg("title")),
+				),
+			),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			is.Equal(c.expected, c.statement.BuildWithDialect(Postgres))
+			// building again must number from the start
+			is.Equal(c.expected, c.statement.BuildWithDialect(Postgres))
+		})
+	}
+}
+
+func TestDefaultPlaceholders(t *testing.T) {
+	is := is.New(t)
+
+	st := Select(Ref("*"), From(Ref("items")), Where(Equals(Ref("id"), Placeholder())), LimitP())
+
+	is.Equal("select * from items where (id = ?) limit ?", st.Build())
+	is.Equal(st.Build(), st.BuildWithDialect(nil))
+}
+
+func TestPostgresDistinctOn(t *testing.T) {
+	is := is.New(t)
+
+	st := Select(
+		Columns(Ref("user_id"), Ref("created_at")),
+		From(Ref("items")),
+		Where(Equals(Ref("kind"), Placeholder())),
+		DistinctOn(Ref("user_id"), Func("lower", Ref("kind"))),
+		OrderBy("user_id", "created_at desc"),
+	)
+
+	is.Equal(
+		"select distinct on (user_id, lower(kind)) user_id, created_at from items where (kind = $1) order by user_id, created_at desc",
+		st.BuildWithDialect(Postgres),
+	)
+}
+
+func TestConcat(t *testing.T) {
+	expr := As(Concat(Ref("first"), Const(" "), Ref("last")), "full_name")
+
+	cases := []struct {
+		description string
+		expected    string
+		dialect     Dialect
+	}{
+		{
+			description: "default",
+			expected:    "select first || ' ' || last as full_name from users",
+		},
+		{
+			description: "postgres",
+			expected:    "select first || ' ' || last as full_name from users",
+			dialect:     Postgres,
+		},
+		{
+			description: "mysql",
+			expected:    "select concat(first, ' ', last) as full_name from users",
+			dialect:     MySQL,
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			is.Equal(c.expected, Select(expr, From(Ref("users"))).BuildWithDialect(c.dialect))
+		})
+	}
+}
+
+func TestMySQL(t *testing.T) {
+	is := is.New(t)
+
+	st := Select(
+		Columns(RefAs("id", "user.id"), Const(`it's C:\temp`)),
+		From(Ref("users")),
+		Where(Equals(Ref("id"), Placeholder())),
+	)
+
+	is.Equal("select id as `user.id`, 'it''s C:\\\\temp' from users where (id = ?)", st.BuildWithDialect(MySQL))
+}
+
+func TestMySQLOnDuplicateKeyUpdate(t *testing.T) {
+	is := is.New(t)
+
+	st := Insert(
+		Ref("items"),
+		InsertColumns("id", "name"),
+		Values(Placeholder(), Placeholder()),
+		OnDuplicateKeyUpdate(Equals(Ref("name"), Placeholder())),
+	)
+
+	is.Equal("insert into items (id, name) values (?, ?) on duplicate key update name = ?", st.BuildWithDialect(MySQL))
+
+	st = Insert(
+		Ref("items"),
+		InsertColumns("id", "name"),
+		Values(Placeholder(), Placeholder()),
+		OnConflict("id"),
+		DoUpdate(Equals(Ref("name"), Placeholder())),
+	)
+
+	is.Equal("insert into items (id, name) values (?, ?) on duplicate key update name = ?", st.BuildWithDialect(MySQL))
+	is.Equal("insert into items (id, name) values ($1, $2) on conflict (id) do update set name = $3", st.BuildWithDialect(Postgres))
+}
+
+func TestQuote(t *testing.T) {
+	cases := []struct {
+		description string
+		expected    string
+		dialect     Dialect
+		expr        Expression
+	}{
+		{
+			description: "keyword",
+			expected:    `"order"`,
+			expr:        Quote("order"),
+		},
+		{
+			description: "multiple parts",
+			expected:    `"public"."order"."id"`,
+			expr:        Quote("public.order.id"),
+		},
+		{
+			description: "part containing a dot",
+			expected:    `"public"."my.table"`,
+			expr:        QuoteParts("public", "my.table"),
+		},
+		{
+			description: "part containing a quote",
+			expected:    `"my""table"`,
+			expr:        Quote(`my"table`),
+		},
+		{
+			description: "postgres",
+			expected:    `"public"."user"`,
+			dialect:     Postgres,
+			expr:        Quote("public.user"),
+		},
+		{
+			description: "mysql",
+			expected:    "`shop`.`order`",
+			dialect:     MySQL,
+			expr:        Quote("shop.order"),
+		},
+		{
+			description: "in a statement",
+			expected:    `select "o"."desc" from "order" as o where ("o"."user" = ?)`,
+			expr: Select(
+				Quote("o.desc"),
+				From(As(Quote("order"), "o")),
+				Where(Equals(Quote("o.user"), Placeholder())),
+			),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			is.Equal(c.expected, NewBuildContext(c.dialect).Build(c.expr))
+		})
+	}
+}
+
+func TestCaseInsensitiveLike(t *testing.T) {
+	st := Select(Ref("*"), From(Ref("users")), Where(CaseInsensitiveLike(Ref("name"), Placeholder())))
+
+	cases := []struct {
+		description string
+		expected    string
+		dialect     Dialect
+	}{
+		{
+			description: "default",
+			expected:    "select * from users where (lower(name) like lower(?))",
+		},
+		{
+			description: "postgres",
+			expected:    "select * from users where (name ilike $1)",
+			dialect:     Postgres,
+		},
+		{
+			description: "mysql",
+			expected:    "select * from users where (lower(name) like lower(?))",
+			dialect:     MySQL,
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			is.Equal(c.expected, st.BuildWithDialect(c.dialect))
+		})
+	}
+}
+
+func TestRegexMatch(t *testing.T) {
+	cases := []struct {
+		description string
+		expected    string
+		mysql       string
+		expr        Expression
+	}{
+		{
+			description: "matches",
+			expected:    "email ~ ?",
+			mysql:       "regexp_like(email, ?, 'c')",
+			expr:        Matches(Ref("email"), Placeholder()),
+		},
+		{
+			description: "matches ignoring case",
+			expected:    "email ~* ?",
+			mysql:       "regexp_like(email, ?, 'i')",
+			expr:        MatchesCI(Ref("email"), Placeholder()),
+		},
+		{
+			description: "not matches",
+			expected:    "email !~ '^a'",
+			mysql:       "not regexp_like(email, '^a', 'c')",
+			expr:        NotMatches(Ref("email"), Const("^a")),
+		},
+		{
+			description: "not matches ignoring case",
+			expected:    "email !~* ?",
+			mysql:       "not regexp_like(email, ?, 'i')",
+			expr:        NotMatchesCI(Ref("email"), Placeholder()),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			is.Equal(c.expected, c.expr.Build())
+			is.Equal(c.mysql, NewBuildContext(MySQL).Build(c.expr))
+		})
+	}
+}
+
+func TestDefaultDialect(t *testing.T) {
+	is := is.New(t)
+	defer SetDefaultDialect(nil)
+
+	st := Select(As(Ref("id"), "user id"), From(Ref("users")), Where(Equals(Ref("id"), Placeholder()), Equals(Ref("team"), Placeholder())))
+
+	is.Equal(SQLite, DefaultDialect())
+	is.Equal(`select id as "user id" from users where (id = ? and team = ?)`, st.Build())
+
+	SetDefaultDialect(Postgres)
+	is.Equal(`select id as "user id" from users where (id = $1 and team = $2)`, st.Build())
+	is.Equal("select id as `user id` from users where (id = ? and team = ?)", st.BuildWithDialect(MySQL))
+
+	SetDefaultDialect(nil)
+	is.Equal(SQLite, DefaultDialect())
+}
+
+func TestRegisterDialect(t *testing.T) {
+	is := is.New(t)
+
+	for _, name := range []string{"sqlite", "postgres", "mysql", "sqlserver"} {
+		_, ok := LookupDialect(name)
+		is.True(ok)
+	}
+
+	_, ok := LookupDialect("oracle")
+	is.True(!ok)
+
+	RegisterDialect("cockroach", Postgres)
+
+	d, ok := LookupDialect("cockroach")
+	is.True(ok)
+	is.Equal(Postgres, d)
+}
+
+func TestTableColumn(t *testing.T) {
+	cases := []struct {
+		description string
+		expected    string
+		dialect     Dialect
+		expr        Expression
+	}{
+		{
+			description: "table",
+			expected:    `"public"."users"`,
+			expr:        Table("public", "users"),
+		},
+		{
+			description: "table without schema",
+			expected:    `"users"`,
+			expr:        Table("", "users"),
+		},
+		{
+			description: "column",
+			expected:    `"u"."id"`,
+			expr:        Column("u", "id"),
+		},
+		{
+			description: "mysql",
+			expected:    "`shop`.`order`",
+			dialect:     MySQL,
+			expr:        Table("shop", "order"),
+		},
+		{
+			description: "in a statement",
+			expected:    `select "u"."id", "o"."total" from "public"."users" as u join "public"."order" as o on "o"."user_id" = "u"."id" where ("u"."id" = $1)`,
+			dialect:     Postgres,
+			expr: Select(
+				Columns(Column("u", "id"), Column("o", "total")),
+				From(As(Table("public", "users"), "u")),
+				Join(As(Table("public", "order"), "o"), Equals(Column("o", "user_id"), Column("u", "id"))),
+				Where(Equals(Column("u", "id"), Placeholder())),
+			),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			is.Equal(c.expected, NewBuildContext(c.dialect).Build(c.expr))
+		})
+	}
+}
+
+func TestOffsetFetch(t *testing.T) {
+	cases := []struct {
+		description string
+		expected    string
+		args        []interface{}
+		dialect     Dialect
+		statement   Statement
+	}{
+		{
+			description: "limit and offset",
+			expected:    "select * from items order by id limit 10 offset 20",
+			statement:   Select(Ref("*"), From(Ref("items")), OrderBy("id"), Limit(10), Offset(20)),
+		},
+		{
+			description: "offset fetch",
+			expected:    "select * from items order by id offset 20 rows fetch next 10 rows only",
+			dialect:     SQLServer,
+			statement:   Select(Ref("*"), From(Ref("items")), OrderBy("id"), Limit(10), Offset(20)),
+		},
+		{
+			description: "fetch without offset",
+			expected:    "select * from items order by id offset 0 rows fetch next 10 rows only",
+			dialect:     SQLServer,
+			statement:   Select(Ref("*"), From(Ref("items")), OrderBy("id"), Limit(10)),
+		},
+		{
+			description: "offset without fetch",
+			expected:    "select * from items order by id offset 5 rows",
+			dialect:     SQLServer,
+			statement:   Select(Ref("*"), From(Ref("items")), OrderBy("id"), Offset(5)),
+		},
+		{
+			description: "fetch without order by",
+			expected:    "select * from items order by (select null) offset 0 rows fetch next 10 rows only",
+			dialect:     SQLServer,
+			statement:   Select(Ref("*"), From(Ref("items")), Limit(10)),
+		},
+		{
+			description: "offset without order by",
+			expected:    "select * from items order by (select null) offset 5 rows",
+			dialect:     SQLServer,
+			statement:   Select(Ref("*"), From(Ref("items")), Offset(5)),
+		},
+		{
+			description: "limit placeholder is numbered before offset",
+			expected:    "select * from [order] where (id > @p1) order by id offset @p3 rows fetch next @p2 rows only",
+			args:        []interface{}{1},
+			dialect:     SQLServer,
+			statement:   Select(Ref("*"), From(Quote("order")), Where(Greater(Ref("id"), Arg(1))), OrderBy("id"), LimitP(), OffsetP()),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			sql, args := c.statement.BuildArgsWithDialect(c.dialect)
+			is.Equal(c.expected, sql)
+			is.Equal(c.args, args)
+		})
+	}
+}
+
+func TestPaginationArgOrder(t *testing.T) {
+	st := Select(Ref("*"), From(Ref("items")), Where(Equals(Ref("kind"), Placeholder())), OrderBy("id"), LimitP(), OffsetP())
+
+	cases := []struct {
+		description string
+		expected    string
+		dialect     Dialect
+	}{
+		{
+			description: "default",
+			expected:    "select * from items where (kind = ?) order by id limit ? offset ?",
+			dialect:     SQLite,
+		},
+		{
+			description: "postgres",
+			expected:    "select * from items where (kind = $1) order by id limit $2 offset $3",
+			dialect:     Postgres,
+		},
+		{
+			description: "sqlserver",
+			expected:    "select * from items where (kind = @p1) order by id offset @p3 rows fetch next @p2 rows only",
+			dialect:     SQLServer,
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			is.Equal(c.expected, st.BuildWithDialect(c.dialect))
+		})
+	}
+
+	// positional placeholders can't be built out of order, so they follow the
+	// sql
+	st = Select(Ref("*"), From(Ref("items")), OrderBy("id"), LimitP(), OffsetP(), WithPlaceholder("?"))
+	is.Equal("select * from items order by id offset ? rows fetch next ? rows only", st.BuildWithDialect(SQLServer))
+}
+
+func TestWithPlaceholder(t *testing.T) {
+	sub := Select(Ref("id"), From(Ref("teams")), Where(Equals(Ref("org"), Placeholder())))
+
+	cases := []struct {
+		description string
+		expected    string
+		dialect     Dialect
+		statement   Statement
+	}{
+		{
+			description: "numbered",
+			expected:    "select * from users where (a = $1 and b = $2)",
+			statement:   Select(Ref("*"), From(Ref("users")), Where(Equals(Ref("a"), Placeholder()), Equals(Ref("b"), Arg(1))), WithPlaceholder("$%d")),
+		},
+		{
+			description: "fixed token",
+			expected:    "select * from users where (a = :? and b = :?)",
+			dialect:     Postgres,
+			statement:   Select(Ref("*"), From(Ref("users")), Where(Equals(Ref("a"), Placeholder()), Equals(Ref("b"), Placeholder())), WithPlaceholder(":?")),
+		},
+		{
+			description: "subselect uses outer placeholder",
+			expected:    "select * from users where (a = @p1 and team_id in (select id from teams where (org = @p2)))",
+			statement:   Select(Ref("*"), From(Ref("users")), Where(Equals(Ref("a"), Placeholder()), InSubquery(Ref("team_id"), sub)), WithPlaceholder("@p%d")),
+		},
+		{
+			description: "subselect with its own placeholder",
+			expected:    "select * from users where (a = $1 and team_id in (select id from teams where (org = :2)) and b = $3)",
+			statement: Select(Ref("*"), From(Ref("users")), Where(
+				Equals(Ref("a"), Placeholder()),
+				InSubquery(Ref("team_id"), Select(Ref("id"), From(Ref("teams")), Where(Equals(Ref("org"), Placeholder())), WithPlaceholder(":%d"))),
+				Equals(Ref("b"), Placeholder()),
+			), WithPlaceholder("$%d")),
+		},
+		{
+			description: "only the subselect",
+			expected:    "select * from users where (a = ? and team_id in (select id from teams where (org = $2)))",
+			statement: Select(Ref("*"), From(Ref("users")), Where(
+				Equals(Ref("a"), Placeholder()),
+				InSubquery(Ref("team_id"), Select(Ref("id"), From(Ref("teams")), Where(Equals(Ref("org"), Placeholder())), WithPlaceholder("$%d"))),
+			)),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			is.Equal(c.expected, c.statement.BuildWithDialect(c.dialect))
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package sqlbuilder
+
+import "strings"
+
+// Explain shows the plan for st without running it, building "explain st".
+// Placeholders and args in st are kept as they are.
+func Explain(st Statement) Statement {
+	return Statement{
+		Kind:        _ExplainStatement,
+		Expressions: []Expression{st},
+	}
+}
+
+// ExplainAnalyze runs st and shows its plan along with how long each step
+// took, building "explain analyze st". Since st is run, explaining an insert,
+// update or delete changes rows.
+func ExplainAnalyze(st Statement) Statement {
+	return Statement{
+		Kind:        _ExplainStatement,
+		Expressions: []Expression{Ref("analyze"), st},
+	}
+}
+
+// ExplainWith is like Explain with a list of options, building
+// "explain (analyze, buffers) st". The options are written as they are.
+// Without any options it's the same as Explain.
+func ExplainWith(st Statement, opts ...string) Statement {
+	if len(opts) == 0 {
+		return Explain(st)
+	}
+
+	return Statement{
+		Kind:        _ExplainStatement,
+		Expressions: []Expression{Ref("(" + strings.Join(opts, defaultExpressionDelimeter) + ")"), st},
+	}
+}
@@ -0,0 +1,69 @@
+package sqlbuilder
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestExplain(t *testing.T) {
+	cases := []struct {
+		description string
+		expected    string
+		args        []interface{}
+		statement   Statement
+	}{
+		{
+			description: "select",
+			expected:    "explain select * from users where (id = $1)",
+			args:        []interface{}{1},
+			statement:   Explain(Select(Ref("*"), From(Ref("users")), Where(Equals(Ref("id"), Arg(1))))),
+		},
+		{
+			description: "analyze",
+			expected:    "explain analyze select * from users where (id = $1)",
+			args:        []interface{}{1},
+			statement:   ExplainAnalyze(Select(Ref("*"), From(Ref("users")), Where(Equals(Ref("id"), Arg(1))))),
+		},
+		{
+			description: "options",
+			expected:    "explain (analyze, buffers) update users set name = $1 where (id = $2)",
+			args:        []interface{}{"kyle", 1},
+			statement: ExplainWith(Update(Ref("users"),
+				Set(Equals(Ref("name"), Arg("kyle"))),
+				Where(Equals(Ref("id"), Arg(1))),
+			), "analyze", "buffers"),
+		},
+		{
+			description: "insert",
+			expected:    "explain insert into users (name) values ($1)",
+			args:        []interface{}{"kyle"},
+			statement:   Explain(Insert(Ref("users"), InsertColumns("name"), Values(Arg("kyle")))),
+		},
+		{
+			description: "delete",
+			expected:    "explain delete from users where (id = $1)",
+			args:        []interface{}{1},
+			statement:   ExplainWith(Delete(Ref("users"), Where(Equals(Ref("id"), Arg(1))))),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			sql, args := c.statement.BuildArgsWithDialect(Postgres)
+			is.Equal(c.expected, sql)
+			is.Equal(c.args, args)
+			is.NoErr(c.statement.Validate())
+		})
+	}
+}
+
+func TestExplainValidate(t *testing.T) {
+	is := is.New(t)
+
+	st := Explain(Update(Ref("users"), Set(Equals(Ref("a"), Placeholder())), OrderBy()))
+
+	is.True(errors.Is(st.Validate(), ErrEmptyClause))
+}
@@ -0,0 +1,269 @@
+package sqlbuilder
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RefExpr is a name used as-is, such as a column or table. Ref builds one.
+type RefExpr struct {
+	Name string
+}
+
+func (e RefExpr) Build() string { return e.Name }
+
+// AliasExpr names Expr, building "expr as alias". With Columns the columns of
+// Expr are named too, building "expr as alias(a, b)". As and FromSubselect
+// build one.
+type AliasExpr struct {
+	Expr    Expression
+	Alias   string
+	Columns []string
+}
+
+func (e AliasExpr) Build() string { return e.BuildWith(&BuildContext{}) }
+
+func (e AliasExpr) BuildWith(ctx *BuildContext) string {
+	name := quoteAlias(ctx.Dialect(), e.Alias)
+
+	if len(e.Columns) > 0 {
+		name += "(" + strings.Join(e.Columns, defaultExpressionDelimeter) + ")"
+	}
+
+	return ctx.Build(e.Expr) + " as " + name
+}
+
+// Children returns Expr.
+func (e AliasExpr) Children() []Expression {
+	return []Expression{e.Expr}
+}
+
+// PlaceholderExpr is a placeholder for the dialect being built, such as "?" or
+// "$1", with nothing bound to it. Placeholder builds one.
+type PlaceholderExpr struct{}
+
+func (e PlaceholderExpr) Build() string { return e.BuildWith(&BuildContext{}) }
+
+func (e PlaceholderExpr) BuildWith(ctx *BuildContext) string {
+	return ctx.Placeholder()
+}
+
+// ArgExpr is a placeholder bound to Value. Arg builds one and NamedArg builds
+// one with a Name, which builds the named placeholder ":name".
+type ArgExpr struct {
+	Name  string
+	Value interface{}
+}
+
+func (e ArgExpr) Build() string { return e.BuildWith(&BuildContext{}) }
+
+func (e ArgExpr) BuildWith(ctx *BuildContext) string {
+	if e.Name != "" {
+		return ctx.BindNamed(e.Name, e.Value)
+	}
+
+	return ctx.BindArg(e.Value)
+}
+
+// RawExpr is sql built exactly as it's given, with each "?" in it bound to the
+// arg in the same position. Raw and RawArgs build one.
+type RawExpr struct {
+	SQL  string
+	Args []interface{}
+}
+
+func (e RawExpr) Build() string { return e.BuildWith(&BuildContext{}) }
+
+func (e RawExpr) BuildWith(ctx *BuildContext) string {
+	if len(e.Args) == 0 {
+		return e.SQL
+	}
+
+	parts := strings.Split(e.SQL, "?")
+
+	if len(parts)-1 != len(e.Args) {
+		panic(fmt.Sprintf("sqlbuilder: raw sql has %d placeholders for %d args", len(parts)-1, len(e.Args)))
+	}
+
+	var b strings.Builder
+
+	for i, part := range parts {
+		if i > 0 {
+			b.WriteString(ctx.BindArg(e.Args[i-1]))
+		}
+
+		b.WriteString(part)
+	}
+
+	return b.String()
+}
+
+// PredicateExpr is a binary operation, "left op right", or a postfix one,
+// "left op", when Right is nil. Equals, In, Like and the other comparisons
+// build one, so their parts can be inspected after they're built, such as
+// with Walk.
+type PredicateExpr struct {
+	Op    string
+	Left  Expression
+	Right Expression
+}
+
+func (e PredicateExpr) Build() string { return e.BuildWith(&BuildContext{}) }
+
+func (e PredicateExpr) BuildWith(ctx *BuildContext) string {
+	s := ctx.Build(e.Left) + " " + e.Op
+
+	if e.Right != nil {
+		s += " " + ctx.Build(e.Right)
+	}
+
+	return s
+}
+
+// Children returns Left and Right.
+func (e PredicateExpr) Children() []Expression {
+	return []Expression{e.Left, e.Right}
+}
+
+// WrapExpr is an expression wrapped in parentheses, "(expr)". Wrap, And, Or
+// and the arithmetic operators build one.
+type WrapExpr struct {
+	Expr Expression
+}
+
+func (e WrapExpr) Build() string { return e.BuildWith(&BuildContext{}) }
+
+func (e WrapExpr) BuildWith(ctx *BuildContext) string {
+	return "(" + ctx.Build(e.Expr) + ")"
+}
+
+// Children returns Expr.
+func (e WrapExpr) Children() []Expression {
+	return []Expression{e.Expr}
+}
+
+// FuncExpr is a function call, "name(a, b)". Func and the aggregates like
+// Count build one. Distinct builds "name(distinct a, b)" and OrderBy builds
+// "name(a, b order by c)", see FuncDistinct and FuncOrdered.
+type FuncExpr struct {
+	Name     string
+	Args     []Expression
+	Distinct bool
+	OrderBy  []Expression
+}
+
+func (e FuncExpr) Build() string { return e.BuildWith(&BuildContext{}) }
+
+func (e FuncExpr) BuildWith(ctx *BuildContext) string {
+	args := ctx.Build(MultiExpression{
+		Delimeter:   defaultExpressionDelimeter,
+		Expressions: e.Args,
+	})
+
+	if e.Distinct {
+		args = "distinct " + args
+	}
+
+	if len(e.OrderBy) > 0 {
+		args += " " + ctx.Build(orderByClause{columns: e.OrderBy})
+	}
+
+	return e.Name + "(" + args + ")"
+}
+
+// Children returns Args followed by OrderBy.
+func (e FuncExpr) Children() []Expression {
+	return append(append([]Expression{}, e.Args...), e.OrderBy...)
+}
+
+// UnaryExpr is a prefix operation, "op expr". Not and Exists build one.
+type UnaryExpr struct {
+	Op   string
+	Expr Expression
+}
+
+func (e UnaryExpr) Build() string { return e.BuildWith(&BuildContext{}) }
+
+func (e UnaryExpr) BuildWith(ctx *BuildContext) string {
+	return e.Op + " " + ctx.Build(e.Expr)
+}
+
+// Children returns Expr.
+func (e UnaryExpr) Children() []Expression {
+	return []Expression{e.Expr}
+}
+
+// nodeExpr is built by a closure from exprs, for expressions like Concat and
+// Window whose sql depends on the dialect being built or doesn't fit the other
+// nodes. Walk visits exprs as its children.
+type nodeExpr struct {
+	exprs []Expression
+	build func(ctx *BuildContext) string
+}
+
+func (e nodeExpr) Build() string { return e.BuildWith(&BuildContext{}) }
+
+func (e nodeExpr) BuildWith(ctx *BuildContext) string {
+	return e.build(ctx)
+}
+
+// Children returns the expressions e is built from.
+func (e nodeExpr) Children() []Expression {
+	return e.exprs
+}
+
+// CastExpr converts Expr to Type, building "cast(expr as typ)", or the postgres
+// "expr::typ" when Shorthand is set. Cast and CastOp build one.
+type CastExpr struct {
+	Expr      Expression
+	Type      string
+	Shorthand bool
+}
+
+func (e CastExpr) Build() string { return e.BuildWith(&BuildContext{}) }
+
+func (e CastExpr) BuildWith(ctx *BuildContext) string {
+	operand := ctx.Build(e.Expr)
+
+	if !e.Shorthand {
+		return "cast(" + operand + " as " + e.Type + ")"
+	}
+
+	if !isTerm(operand) {
+		operand = "(" + operand + ")"
+	}
+
+	return operand + "::" + e.Type
+}
+
+// Children returns Expr.
+func (e CastExpr) Children() []Expression {
+	return []Expression{e.Expr}
+}
+
+// FilterExpr limits the rows an aggregate aggregates, building "agg filter
+// (where a and b)", or just "agg" without any Predicates. Filter builds one.
+type FilterExpr struct {
+	Agg        Expression
+	Predicates []Expression
+}
+
+func (e FilterExpr) Build() string { return e.BuildWith(&BuildContext{}) }
+
+func (e FilterExpr) BuildWith(ctx *BuildContext) string {
+	if len(e.Predicates) == 0 {
+		return ctx.Build(e.Agg)
+	}
+
+	predicates := MultiExpression{
+		Delimeter:   " and ",
+		Expressions: e.Predicates,
+	}
+
+	return ctx.Build(e.Agg) + " filter (where " + ctx.Build(predicates) + ")"
+}
+
+// Children returns Agg followed by Predicates.
+func (e FilterExpr) Children() []Expression {
+	return append([]Expression{e.Agg}, e.Predicates...)
+}
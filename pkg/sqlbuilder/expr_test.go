@@ -0,0 +1,136 @@
+package sqlbuilder
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestPredicateExpr(t *testing.T) {
+	is := is.New(t)
+
+	eq, ok := Equals(Ref("a"), Placeholder()).(PredicateExpr)
+	is.True(ok)
+	is.Equal("=", eq.Op)
+	is.Equal("a", eq.Left.Build())
+	is.Equal("a = ?", eq.Build())
+
+	null := IsNull(Ref("deleted_at")).(PredicateExpr)
+	is.Equal("is null", null.Op)
+	is.Equal(nil, null.Right)
+
+	// fields can be changed to rewrite a predicate
+	eq.Op = "<>"
+	is.Equal("a <> ?", eq.Build())
+}
+
+func TestWrapAndFuncExpr(t *testing.T) {
+	is := is.New(t)
+
+	or := Or(Equals(Ref("a"), Ref("b")), Bool("c")).(WrapExpr)
+	is.Equal(2, len(or.Expr.(MultiExpression).Expressions))
+	is.Equal("(a = b or c)", or.Build())
+
+	count := Count(Ref("id")).(FuncExpr)
+	is.Equal("count", count.Name)
+	is.Equal(1, len(count.Args))
+	is.Equal("count(id)", count.Build())
+	is.Equal("now()", Func("now").Build())
+}
+
+func TestStructuredExpressions(t *testing.T) {
+	cases := []struct {
+		description string
+		expected    string
+		expr        Expression
+		node        Expression
+	}{
+		{
+			description: "not",
+			expected:    "not (a = b)",
+			expr:        Not(Equals(Ref("a"), Ref("b"))),
+			node:        UnaryExpr{Op: "not", Expr: Wrap(Equals(Ref("a"), Ref("b")))},
+		},
+		{
+			description: "exists",
+			expected:    "exists (select 1 from bans)",
+			expr:        Exists(Select(Ref("1"), From(Ref("bans")))),
+			node:        UnaryExpr{Op: "exists", Expr: Wrap(Select(Ref("1"), From(Ref("bans"))))},
+		},
+		{
+			description: "cast",
+			expected:    "cast(age as text)",
+			expr:        Cast(Ref("age"), "text"),
+			node:        CastExpr{Expr: Ref("age"), Type: "text"},
+		},
+		{
+			description: "cast shorthand",
+			expected:    "(a + b)::text",
+			expr:        CastOp(Ref("a + b"), "text"),
+			node:        CastExpr{Expr: Ref("a + b"), Type: "text", Shorthand: true},
+		},
+		{
+			description: "filter",
+			expected:    "count(*) filter (where paid)",
+			expr:        Filter(CountAll(), Bool("paid")),
+			node:        FilterExpr{Agg: CountAll(), Predicates: []Expression{Bool("paid")}},
+		},
+		{
+			description: "func distinct",
+			expected:    "count(distinct id)",
+			expr:        FuncDistinct("count", Ref("id")),
+			node:        FuncExpr{Name: "count", Args: []Expression{Ref("id")}, Distinct: true},
+		},
+		{
+			description: "func ordered",
+			expected:    "string_agg(name, ', ' order by name)",
+			expr:        FuncOrdered("string_agg", []Expression{Ref("name"), Const(", ")}, "name"),
+			node:        FuncExpr{Name: "string_agg", Args: []Expression{Ref("name"), Const(", ")}, OrderBy: []Expression{Ref("name")}},
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			is.Equal(c.expected, c.expr.Build())
+			is.Equal(c.expected, c.node.Build())
+			is.Equal(fmt.Sprintf("%T", c.node), fmt.Sprintf("%T", c.expr))
+		})
+	}
+}
+
+func TestWalkStructuredExpressions(t *testing.T) {
+	is := is.New(t)
+
+	banned := Select(Ref("user_id"), From(Ref("bans")), Where(Equals(Ref("reason"), Placeholder())))
+	st := Select(Columns(Ref("id"), Func("lower", Ref("name"))), From(Ref("users")),
+		Where(Or(NotInSubquery(Ref("id"), banned), Bool("admin")), Greater(Ref("age"), Placeholder())),
+		Where(Not(Exists(banned))),
+	)
+
+	var (
+		ops        []string
+		funcs      []string
+		statements int
+	)
+
+	Walk(st, func(expr Expression) bool {
+		switch e := expr.(type) {
+		case PredicateExpr:
+			ops = append(ops, e.Op)
+		case FuncExpr:
+			funcs = append(funcs, e.Name)
+		case UnaryExpr:
+			ops = append(ops, e.Op)
+		case Statement:
+			statements++
+		}
+
+		return true
+	})
+
+	is.Equal([]string{"not in", "=", ">", "not", "exists", "="}, ops)
+	is.Equal([]string{"lower"}, funcs)
+	is.Equal(3, statements) // the subqueries are reached through the predicates
+}
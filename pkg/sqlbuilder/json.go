@@ -0,0 +1,33 @@
+package sqlbuilder
+
+// The JSON operators are Postgres specific and work on json and jsonb values.
+
+// JSONGet gets the object field or array element key of col as json, building
+// "col -> key".
+func JSONGet(col, key Expression) Expression {
+	return Predicate("->", col, key)
+}
+
+// JSONGetText gets the object field or array element key of col as text,
+// building "col ->> key".
+func JSONGetText(col, key Expression) Expression {
+	return Predicate("->>", col, key)
+}
+
+// JSONPath gets the value at path in col as json, building "col #> path". The
+// path is a text array such as Const("{a,b}").
+func JSONPath(col, path Expression) Expression {
+	return Predicate("#>", col, path)
+}
+
+// JSONPathText is like JSONPath but gets the value as text, building
+// "col #>> path".
+func JSONPathText(col, path Expression) Expression {
+	return Predicate("#>>", col, path)
+}
+
+// JSONContains is true when col contains the jsonb value, building
+// "col @> value".
+func JSONContains(col, value Expression) Expression {
+	return Predicate("@>", col, value)
+}
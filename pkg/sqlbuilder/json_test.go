@@ -0,0 +1,74 @@
+package sqlbuilder
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestJSON(t *testing.T) {
+	cases := []struct {
+		description string
+		expected    string
+		expr        Expression
+	}{
+		{
+			description: "get",
+			expected:    "data -> 'address'",
+			expr:        JSONGet(Ref("data"), Const("address")),
+		},
+		{
+			description: "get text",
+			expected:    "data ->> 'name'",
+			expr:        JSONGetText(Ref("data"), Const("name")),
+		},
+		{
+			description: "nested",
+			expected:    "data -> 'address' ->> 'city' as city",
+			expr:        As(JSONGetText(JSONGet(Ref("data"), Const("address")), Const("city")), "city"),
+		},
+		{
+			description: "array element",
+			expected:    "data -> 'tags' -> 0",
+			expr:        JSONGet(JSONGet(Ref("data"), Const("tags")), Ref("0")),
+		},
+		{
+			description: "path",
+			expected:    "data #> '{address,city}'",
+			expr:        JSONPath(Ref("data"), Const("{address,city}")),
+		},
+		{
+			description: "path text",
+			expected:    "data #>> ?",
+			expr:        JSONPathText(Ref("data"), Placeholder()),
+		},
+		{
+			description: "contains",
+			expected:    `data @> '{"active": true}'`,
+			expr:        JSONContains(Ref("data"), Const(`{"active": true}`)),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			is.Equal(c.expected, c.expr.Build())
+		})
+	}
+}
+
+func TestJSONInStatement(t *testing.T) {
+	is := is.New(t)
+
+	sql, args := Select(
+		As(JSONGetText(Ref("data"), Const("name")), "name"),
+		From(Ref("users")),
+		Where(
+			Equals(JSONGetText(Ref("data"), Const("team")), Arg("a")),
+			JSONContains(Ref("data"), Arg(`{"active": true}`)),
+		),
+	).BuildArgsWithDialect(Postgres)
+
+	is.Equal("select data ->> 'name' as name from users where (data ->> 'team' = $1 and data @> $2)", sql)
+	is.Equal([]interface{}{"a", `{"active": true}`}, args)
+}
@@ -0,0 +1,54 @@
+package sqlbuilder
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// NamedArg binds value to the named placeholder ":name". Using the same name
+// more than once builds the same placeholder each time, so it must be bound to
+// the same value; building a statement that binds a name to different values
+// panics. Named args are collected with BuildNamed, which works with named
+// query helpers like sqlx.NamedExec. NamedArg panics when name isn't a plain
+// identifier.
+func NamedArg(name string, value interface{}) Expression {
+	if !isIdentifier(name) {
+		panic(fmt.Sprintf("sqlbuilder: invalid named arg %q", name))
+	}
+
+	return ArgExpr{Name: name, Value: value}
+}
+
+// BindNamed binds value to name and returns the named placeholder for it. It
+// panics when name is already bound to a different value.
+func (ctx *BuildContext) BindNamed(name string, value interface{}) string {
+	if ctx.named == nil {
+		ctx.named = make(map[string]interface{})
+	}
+
+	if bound, ok := ctx.named[name]; ok && !reflect.DeepEqual(bound, value) {
+		panic(fmt.Sprintf("sqlbuilder: named arg %q bound to both %v and %v", name, bound, value))
+	}
+
+	ctx.named[name] = value
+
+	if ctx.debug {
+		return renderValue(ctx.Dialect(), value)
+	}
+
+	return ":" + name
+}
+
+// Named returns the named args bound so far.
+func (ctx *BuildContext) Named() map[string]interface{} {
+	return ctx.named
+}
+
+// BuildNamed builds the statement and returns it along with every argument
+// bound with NamedArg, keyed by name.
+func (s Statement) BuildNamed() (string, map[string]interface{}) {
+	ctx := &BuildContext{}
+	sql := s.BuildWith(ctx)
+
+	return sql, ctx.Named()
+}
@@ -0,0 +1,90 @@
+package sqlbuilder
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestBuildNamed(t *testing.T) {
+	cases := []struct {
+		description string
+		expected    string
+		args        map[string]interface{}
+		statement   Statement
+	}{
+		{
+			description: "named args",
+			expected:    "select * from users where (id = :id and team = :team)",
+			args:        map[string]interface{}{"id": 1, "team": "a"},
+			statement: Select(Ref("*"), From(Ref("users")), Where(
+				Equals(Ref("id"), NamedArg("id", 1)),
+				Equals(Ref("team"), NamedArg("team", "a")),
+			)),
+		},
+		{
+			description: "reused name",
+			expected:    "select * from users where ((created_by = :user or updated_by = :user))",
+			args:        map[string]interface{}{"user": 7},
+			statement: Select(Ref("*"), From(Ref("users")), Where(Or(
+				Equals(Ref("created_by"), NamedArg("user", 7)),
+				Equals(Ref("updated_by"), NamedArg("user", 7)),
+			))),
+		},
+		{
+			description: "reused name with a slice",
+			expected:    "select * from users where ((id = any(:ids) or team_id = any(:ids)))",
+			args:        map[string]interface{}{"ids": []int{1, 2}},
+			statement: Select(Ref("*"), From(Ref("users")), Where(Or(
+				AnyArray(Ref("id"), NamedArg("ids", []int{1, 2})),
+				AnyArray(Ref("team_id"), NamedArg("ids", []int{1, 2})),
+			))),
+		},
+		{
+			description: "update",
+			expected:    "update users set name = :name where (id = :id)",
+			args:        map[string]interface{}{"id": 1, "name": "kyle"},
+			statement: Update(Ref("users"),
+				Set(Equals(Ref("name"), NamedArg("name", "kyle"))),
+				Where(Equals(Ref("id"), NamedArg("id", 1))),
+			),
+		},
+		{
+			description: "no named args",
+			expected:    "select * from users",
+			statement:   Select(Ref("*"), From(Ref("users"))),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			sql, args := c.statement.BuildNamed()
+			is.Equal(c.expected, sql)
+			is.Equal(c.args, args)
+		})
+	}
+}
+
+func TestNamedArgInvalidName(t *testing.T) {
+	is := is.New(t)
+
+	defer func() {
+		is.True(recover() != nil)
+	}()
+
+	NamedArg("id; drop table users", 1)
+}
+
+func TestNamedArgConflict(t *testing.T) {
+	is := is.New(t)
+
+	defer func() {
+		is.True(recover() != nil)
+	}()
+
+	Select(Ref("*"), From(Ref("users")), Where(
+		Equals(Ref("created_by"), NamedArg("user", 7)),
+		Equals(Ref("updated_by"), NamedArg("user", 8)),
+	)).BuildNamed()
+}
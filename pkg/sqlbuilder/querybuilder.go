@@ -0,0 +1,93 @@
+package sqlbuilder
+
+// QueryBuilder is a chainable alternative to building a select with
+// StatementOptions. Each method applies the StatementOption of the same name,
+// so the two styles build the same SQL and can be mixed with Apply and
+// ToStatement.
+type QueryBuilder struct {
+	st Statement
+}
+
+// NewSelect starts a select of cols. Without any cols it selects "*".
+func NewSelect(cols ...Expression) *QueryBuilder {
+	if len(cols) == 0 {
+		return &QueryBuilder{st: SelectStar()}
+	}
+
+	return &QueryBuilder{st: Select(Columns(cols...))}
+}
+
+// Apply applies StatementOptions that don't have a QueryBuilder method.
+func (b *QueryBuilder) Apply(opts ...StatementOption) *QueryBuilder {
+	for _, opt := range opts {
+		opt(&b.st)
+	}
+
+	return b
+}
+
+func (b *QueryBuilder) Distinct() *QueryBuilder {
+	return b.Apply(Distinct())
+}
+
+func (b *QueryBuilder) From(tables ...Expression) *QueryBuilder {
+	return b.Apply(From(tables...))
+}
+
+func (b *QueryBuilder) Join(table Expression, predicates ...Expression) *QueryBuilder {
+	return b.Apply(Join(table, predicates...))
+}
+
+func (b *QueryBuilder) LeftJoin(table Expression, predicates ...Expression) *QueryBuilder {
+	return b.Apply(LeftJoin(table, predicates...))
+}
+
+func (b *QueryBuilder) Where(predicates ...Expression) *QueryBuilder {
+	return b.Apply(Where(predicates...))
+}
+
+func (b *QueryBuilder) GroupBy(cols ...string) *QueryBuilder {
+	return b.Apply(GroupBy(cols...))
+}
+
+func (b *QueryBuilder) GroupByExpr(exprs ...Expression) *QueryBuilder {
+	return b.Apply(GroupByExpr(exprs...))
+}
+
+func (b *QueryBuilder) Having(predicates ...Expression) *QueryBuilder {
+	return b.Apply(Having(predicates...))
+}
+
+func (b *QueryBuilder) OrderBy(cols ...string) *QueryBuilder {
+	return b.Apply(OrderBy(cols...))
+}
+
+func (b *QueryBuilder) OrderByExpr(cols ...Expression) *QueryBuilder {
+	return b.Apply(OrderByExpr(cols...))
+}
+
+func (b *QueryBuilder) Limit(n int) *QueryBuilder {
+	return b.Apply(Limit(n))
+}
+
+func (b *QueryBuilder) Offset(n int) *QueryBuilder {
+	return b.Apply(Offset(n))
+}
+
+// Err returns the first error recorded by the statement built so far.
+func (b *QueryBuilder) Err() error {
+	return b.st.Err()
+}
+
+// ToStatement returns the statement built so far.
+func (b *QueryBuilder) ToStatement() Statement {
+	return b.st
+}
+
+func (b *QueryBuilder) Build() string {
+	return b.st.Build()
+}
+
+func (b *QueryBuilder) BuildArgs() (string, []interface{}) {
+	return b.st.BuildArgs()
+}
@@ -0,0 +1,78 @@
+package sqlbuilder
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestQueryBuilder(t *testing.T) {
+	cases := []struct {
+		description string
+		builder     *QueryBuilder
+		statement   Statement
+	}{
+		{
+			description: "simple select",
+			builder:     NewSelect(Ref("id"), Ref("name")).From(Ref("users")),
+			statement:   Select(Columns(Ref("id"), Ref("name")), From(Ref("users"))),
+		},
+		{
+			description: "full select",
+			builder: NewSelect(Ref("u.id"), As(CountAll(), "n")).
+				From(RefAs("users", "u")).
+				LeftJoin(RefAs("orders", "o"), Equals(Ref("o.user_id"), Ref("u.id"))).
+				Where(Equals(Ref("u.active"), Arg(true))).
+				GroupBy("u.id").
+				Having(Greater(CountAll(), Arg(1))).
+				OrderBy("n").
+				Limit(10).
+				Offset(20),
+			statement: Select(
+				Columns(Ref("u.id"), As(CountAll(), "n")),
+				From(RefAs("users", "u")),
+				LeftJoin(RefAs("orders", "o"), Equals(Ref("o.user_id"), Ref("u.id"))),
+				Where(Equals(Ref("u.active"), Arg(true))),
+				GroupBy("u.id"),
+				Having(Greater(CountAll(), Arg(1))),
+				OrderBy("n"),
+				Limit(10),
+				Offset(20),
+			),
+		},
+		{
+			description: "no columns",
+			builder:     NewSelect().From(Ref("users")),
+			statement:   Select(Ref("*"), From(Ref("users"))),
+		},
+		{
+			description: "mixed with options",
+			builder:     NewSelect(Ref("*")).From(Ref("jobs")).Apply(ForUpdate(), SkipLocked()),
+			statement:   Select(Ref("*"), From(Ref("jobs")), ForUpdate(), SkipLocked()),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			sql, args := c.builder.BuildArgs()
+			expectedSQL, expectedArgs := c.statement.BuildArgs()
+
+			is.Equal(expectedSQL, sql)
+			is.Equal(expectedArgs, args)
+			is.Equal(expectedSQL, c.builder.ToStatement().Build())
+		})
+	}
+}
+
+func ExampleQueryBuilder() {
+	sql := NewSelect(Ref("id"), Ref("name")).
+		From(Ref("users")).
+		Where(Equals(Ref("active"), Placeholder())).
+		OrderBy("name").
+		Build()
+
+	fmt.Println(sql)
+	// Output: select id, name from users where (active = ?) order by name
+}
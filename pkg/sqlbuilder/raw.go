@@ -0,0 +1,25 @@
+package sqlbuilder
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Raw builds sql exactly as it's given. It isn't escaped or checked in any
+// way, so making sure it's valid and safe is up to the caller. Never build
+// Raw from user input; use RawArgs to pass values instead.
+func Raw(sql string) Expression {
+	return RawExpr{SQL: sql}
+}
+
+// RawArgs is like Raw but each "?" in sql is replaced with a placeholder for
+// the dialect being built, bound to the arg in the same position. The args are
+// collected by BuildArgs in the order they appear in the statement. RawArgs
+// panics when the number of "?" doesn't match the number of args.
+func RawArgs(sql string, args ...interface{}) Expression {
+	if n := strings.Count(sql, "?"); n != len(args) {
+		panic(fmt.Sprintf("sqlbuilder: raw sql has %d placeholders for %d args", n, len(args)))
+	}
+
+	return RawExpr{SQL: sql, Args: args}
+}
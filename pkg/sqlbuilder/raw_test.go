@@ -0,0 +1,62 @@
+package sqlbuilder
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestRaw(t *testing.T) {
+	cases := []struct {
+		description string
+		expected    string
+		args        []interface{}
+		dialect     Dialect
+		statement   Statement
+	}{
+		{
+			description: "raw",
+			expected:    "select * from events where (created_at > now() - interval '1 day')",
+			statement:   Select(Ref("*"), From(Ref("events")), Where(Raw("created_at > now() - interval '1 day'"))),
+		},
+		{
+			description: "raw args in order",
+			expected:    "select * from events where (kind = ? and tsrange(?, ?) @> created_at and user_id = ?)",
+			args:        []interface{}{"click", "2020-01-01", "2020-02-01", 7},
+			statement: Select(Ref("*"), From(Ref("events")), Where(
+				Equals(Ref("kind"), Arg("click")),
+				RawArgs("tsrange(?, ?) @> created_at", "2020-01-01", "2020-02-01"),
+				Equals(Ref("user_id"), Arg(7)),
+			)),
+		},
+		{
+			description: "raw args with numbered placeholders",
+			expected:    "select * from events where (kind = $1 and tsrange($2, $3) @> created_at)",
+			args:        []interface{}{"click", "2020-01-01", "2020-02-01"},
+			dialect:     Postgres,
+			statement: Select(Ref("*"), From(Ref("events")), Where(
+				Equals(Ref("kind"), Arg("click")),
+				RawArgs("tsrange(?, ?) @> created_at", "2020-01-01", "2020-02-01"),
+			)),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			sql, args := c.statement.BuildArgsWithDialect(c.dialect)
+			is.Equal(c.expected, sql)
+			is.Equal(c.args, args)
+		})
+	}
+}
+
+func TestRawArgsMismatch(t *testing.T) {
+	is := is.New(t)
+
+	defer func() {
+		is.True(recover() != nil)
+	}()
+
+	RawArgs("a = ? and b = ?", 1)
+}
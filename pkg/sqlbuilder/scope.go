@@ -0,0 +1,62 @@
+package sqlbuilder
+
+// Scope returns a copy of st with predicates added to its where clause, for
+// cross-cutting filters like "tenant_id = ? and deleted_at is null". Selects,
+// updates and deletes are scoped, as is each operand of a compound statement;
+// other statements are returned as they are. Subqueries and common table
+// expressions are left alone since they may not have the scoped columns.
+//
+// Scoping a statement with the same predicates, bound to the same values, more
+// than once only adds them once.
+func Scope(st Statement, predicates ...Expression) Statement {
+	st = st.Clone()
+
+	if st.isCompound() {
+		for i, expr := range st.Expressions {
+			if sub, ok := expr.(Statement); ok {
+				st.Expressions[i] = Scope(sub, predicates...)
+			}
+		}
+
+		return st
+	}
+
+	switch st.Kind {
+	case _SelectStatement, _UpdateStatement, _DeleteStatement:
+	default:
+		return st
+	}
+
+	st.checkExpressions("scope", predicates...)
+
+	if st.err != nil || len(predicates) == 0 {
+		return st
+	}
+
+	where := whereClause{
+		predicates: MultiExpression{
+			Delimeter:   " and ",
+			Expressions: predicates,
+		},
+	}
+
+	if !st.hasWhere(where) {
+		st.Clauses = append(st.Clauses, where)
+	}
+
+	return st
+}
+
+// hasWhere reports whether the statement already has a where clause that
+// builds the same as where, including any values bound to it.
+func (s Statement) hasWhere(where Clause) bool {
+	key := (&BuildContext{debug: true}).Build(where)
+
+	for _, clause := range s.ClausesOfKind(_WhereClause) {
+		if (&BuildContext{debug: true}).Build(clause) == key {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,110 @@
+package sqlbuilder
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func ExampleScope() {
+	st := Select(Ref("*"), From(Ref("projects")), Where(Equals(Ref("owner_id"), Arg(7))), OrderBy("name"))
+	st = Scope(st, Equals(Ref("tenant_id"), Arg(42)), IsNull(Ref("deleted_at")))
+
+	sql, args := st.BuildArgsWithDialect(Postgres)
+
+	fmt.Println(sql)
+	fmt.Println(args)
+
+	// Output:
+	// select * from projects where (owner_id = $1) and (tenant_id = $2 and deleted_at is null) order by name
+	// [7 42]
+}
+
+func TestScope(t *testing.T) {
+	tenant := Equals(Ref("tenant_id"), Placeholder())
+	live := IsNull(Ref("deleted_at"))
+
+	cases := []struct {
+		description string
+		expected    string
+		statement   Statement
+	}{
+		{
+			description: "no where",
+			expected:    "select * from projects where (tenant_id = ? and deleted_at is null)",
+			statement:   Scope(Select(Ref("*"), From(Ref("projects"))), tenant, live),
+		},
+		{
+			description: "scoped twice",
+			expected:    "select * from projects where (tenant_id = ? and deleted_at is null)",
+			statement:   Scope(Scope(Select(Ref("*"), From(Ref("projects"))), tenant, live), tenant, live),
+		},
+		{
+			description: "update",
+			expected:    "update projects set name = ? where (id = ?) and (tenant_id = ?)",
+			statement:   Scope(Update(Ref("projects"), Set(Equals(Ref("name"), Placeholder())), Where(Equals(Ref("id"), Placeholder()))), tenant),
+		},
+		{
+			description: "delete",
+			expected:    "delete from projects where (tenant_id = ?)",
+			statement:   Scope(Delete(Ref("projects")), tenant),
+		},
+		{
+			description: "compound",
+			expected:    "(select id from projects where (tenant_id = ?)) union (select id from archived where (tenant_id = ?)) order by id",
+			statement: Scope(Union(
+				Select(Ref("id"), From(Ref("projects"))),
+				Select(Ref("id"), From(Ref("archived"))),
+				OrderBy("id"),
+			), tenant),
+		},
+		{
+			description: "insert is left alone",
+			expected:    "insert into projects (name) values (?)",
+			statement:   Scope(Insert(Ref("projects"), InsertColumns("name"), Values(Placeholder())), tenant),
+		},
+		{
+			description: "subqueries are left alone",
+			expected:    "select * from projects where (id in (select project_id from stars)) and (tenant_id = ?)",
+			statement:   Scope(Select(Ref("*"), From(Ref("projects")), Where(InSubquery(Ref("id"), Select(Ref("project_id"), From(Ref("stars")))))), tenant),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			is.Equal(c.expected, c.statement.Build())
+		})
+	}
+}
+
+func TestScopeArgs(t *testing.T) {
+	is := is.New(t)
+
+	st := Select(Ref("*"), From(Ref("projects")))
+
+	// different values are different scopes
+	scoped := Scope(Scope(st, Equals(Ref("tenant_id"), Arg(1))), Equals(Ref("tenant_id"), Arg(2)))
+	sql, args := scoped.BuildArgs()
+	is.Equal("select * from projects where (tenant_id = ?) and (tenant_id = ?)", sql)
+	is.Equal([]interface{}{1, 2}, args)
+
+	// the same values aren't added again
+	scoped = Scope(Scope(st, Equals(Ref("tenant_id"), Arg(1))), Equals(Ref("tenant_id"), Arg(1)))
+	sql, args = scoped.BuildArgs()
+	is.Equal("select * from projects where (tenant_id = ?)", sql)
+	is.Equal([]interface{}{1}, args)
+
+	// the statement being scoped is left alone
+	is.Equal("select * from projects", st.Build())
+}
+
+func TestScopeNil(t *testing.T) {
+	is := is.New(t)
+
+	st := Scope(Select(Ref("*"), From(Ref("projects"))), nil)
+
+	is.True(errors.Is(st.Err(), ErrNilExpression))
+}
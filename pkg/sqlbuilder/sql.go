@@ -0,0 +1,42 @@
+package sqlbuilder
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Querier runs queries that return rows. It's implemented by *sql.DB, *sql.Tx
+// and *sql.Conn.
+type Querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// Execer runs queries that don't return rows. It's implemented by *sql.DB,
+// *sql.Tx and *sql.Conn.
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// Query builds st with BuildArgs and runs it with db. When st recorded an
+// error it's returned without running anything.
+func Query(ctx context.Context, db Querier, st Statement) (*sql.Rows, error) {
+	if err := st.Err(); err != nil {
+		return nil, err
+	}
+
+	query, args := st.BuildArgs()
+
+	return db.QueryContext(ctx, query, args...)
+}
+
+// Exec builds st with BuildArgs and runs it with db. When st recorded an
+// error it's returned without running anything.
+func Exec(ctx context.Context, db Execer, st Statement) (sql.Result, error) {
+	if err := st.Err(); err != nil {
+		return nil, err
+	}
+
+	query, args := st.BuildArgs()
+
+	return db.ExecContext(ctx, query, args...)
+}
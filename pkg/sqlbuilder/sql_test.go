@@ -0,0 +1,77 @@
+package sqlbuilder
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+type recordingDB struct {
+	query string
+	args  []interface{}
+	err   error
+}
+
+func (db *recordingDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	db.query, db.args = query, args
+	return nil, db.err
+}
+
+func (db *recordingDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	db.query, db.args = query, args
+	return nil, db.err
+}
+
+func TestQuery(t *testing.T) {
+	is := is.New(t)
+	db := &recordingDB{}
+
+	_, err := Query(context.Background(), db, Select(
+		Ref("*"),
+		From(Ref("items")),
+		Where(Equals(Ref("id"), Arg(1)), Equals(Ref("owner"), Arg("kyle"))),
+	))
+
+	is.NoErr(err)
+	is.Equal("select * from items where (id = ? and owner = ?)", db.query)
+	is.Equal([]interface{}{1, "kyle"}, db.args)
+}
+
+func TestExec(t *testing.T) {
+	is := is.New(t)
+	db := &recordingDB{err: errors.New("boom")}
+
+	_, err := Exec(context.Background(), db, Update(
+		Ref("items"),
+		Set(Equals(Ref("name"), Arg("new"))),
+		Where(Equals(Ref("id"), Arg(1))),
+	))
+
+	is.Equal(db.err, err)
+	is.Equal("update items set name = ? where (id = ?)", db.query)
+	is.Equal([]interface{}{"new", 1}, db.args)
+}
+
+func TestQueryErr(t *testing.T) {
+	is := is.New(t)
+	db := &recordingDB{query: "not run"}
+
+	_, err := Query(context.Background(), db, Select(Ref("*"), From(Ref("items")), Where(nil)))
+	is.True(errors.Is(err, ErrNilExpression))
+	is.Equal("not run", db.query)
+
+	_, err = Exec(context.Background(), db, Update(nil, Set(Equals(Ref("name"), Arg("new")))))
+	is.True(errors.Is(err, ErrNilExpression))
+	is.Equal("not run", db.query)
+}
+
+// the standard library types must satisfy the interfaces
+var (
+	_ Querier = (*sql.DB)(nil)
+	_ Querier = (*sql.Tx)(nil)
+	_ Execer  = (*sql.DB)(nil)
+	_ Execer  = (*sql.Tx)(nil)
+)
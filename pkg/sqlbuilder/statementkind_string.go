@@ -10,11 +10,23 @@ func _() {
 	var x [1]struct{}
 	_ = x[_unknownStatement-0]
 	_ = x[_SelectStatement-1]
+	_ = x[_UpdateStatement-2]
+	_ = x[_InsertStatement-3]
+	_ = x[_DeleteStatement-4]
+	_ = x[_UnionStatement-5]
+	_ = x[_UnionAllStatement-6]
+	_ = x[_IntersectStatement-7]
+	_ = x[_ExceptStatement-8]
+	_ = x[_IntersectAllStatement-9]
+	_ = x[_ExceptAllStatement-10]
+	_ = x[_ExplainStatement-11]
+	_ = x[_TruncateStatement-12]
+	_ = x[_TableStatement-13]
 }
 
-const _StatementKind_name = "_unknownStatementselect"
+const _StatementKind_name = "_unknownStatementselectupdateinsert intodelete fromunionunion allintersectexceptintersect allexcept allexplaintruncate tabletable"
 
-var _StatementKind_index = [...]uint8{0, 17, 23}
+var _StatementKind_index = [...]uint8{0, 17, 23, 29, 40, 51, 56, 65, 74, 80, 93, 103, 110, 124, 129}
 
 func (i StatementKind) String() string {
 	if i >= StatementKind(len(_StatementKind_index)-1) {
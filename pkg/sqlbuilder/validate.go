@@ -0,0 +1,391 @@
+package sqlbuilder
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrInvalidClause is returned when a statement has a clause its kind of
+	// statement doesn't support, like an order by on an insert.
+	ErrInvalidClause = errors.New("sqlbuilder: clause not valid for statement")
+	// ErrDuplicateClause is returned when a clause that can only be used once
+	// shows up more than once.
+	ErrDuplicateClause = errors.New("sqlbuilder: duplicate clause")
+	// ErrEmptyClause is returned when a clause has nothing in it, like a Where
+	// without any predicates.
+	ErrEmptyClause = errors.New("sqlbuilder: empty clause")
+	// ErrMissingClause is returned when a statement is missing a clause its
+	// kind of statement requires, like an update without a set.
+	ErrMissingClause = errors.New("sqlbuilder: missing required clause")
+	// ErrNilExpression is recorded by a statement when a nil expression is
+	// passed to one of its options, like Where(nil).
+	ErrNilExpression = errors.New("sqlbuilder: nil expression")
+	// ErrRowLength is recorded by a statement when rows of values passed to
+	// one of its options don't all have the same length.
+	ErrRowLength = errors.New("sqlbuilder: rows have different lengths")
+	// ErrNilArg is returned by BuildArgsChecked when a nil value is bound with
+	// Arg in a predicate, where comparing to null never matches.
+	ErrNilArg = errors.New("sqlbuilder: nil arg in predicate")
+)
+
+// allowedClauses is the set of clauses each kind of statement can have.
+var allowedClauses = map[StatementKind][]ClauseKind{
+	_SelectStatement: {
+		_FromClause, _JoinClause, _LeftJoinClause, _InnerJoinClause, _RightJoinClause,
+		_FullJoinClause, _CrossJoinClause, _WhereClause, _GroupByClause, _HavingClause,
+		_WindowClause, _OrderByClause, _LimitClause, _OffsetClause, _LockingClause,
+	},
+	_UpdateStatement:   {_SetClause, _UpdateFromClause, _WhereClause, _OrderByClause, _LimitClause, _ReturningClause},
+	_InsertStatement:   {_InsertColumnsClause, _ValuesClause, _DefaultValuesClause, _OnConflictClause, _ReturningClause},
+	_DeleteStatement:   {_UsingClause, _WhereClause, _OrderByClause, _LimitClause, _ReturningClause},
+	_TruncateStatement: {_RestartIdentityClause, _CascadeClause},
+	_TableStatement:    {_OrderByClause, _LimitClause, _OffsetClause},
+}
+
+// singleClauses can only show up once in a statement.
+var singleClauses = []ClauseKind{
+	_InsertColumnsClause, _DefaultValuesClause, _OnConflictClause, _LimitClause, _OffsetClause, _LockingClause,
+	_RestartIdentityClause, _CascadeClause,
+}
+
+// conflictingClauses are pairs of clauses that can't be used together.
+var conflictingClauses = [][2]ClauseKind{
+	{_DefaultValuesClause, _ValuesClause},
+	{_DefaultValuesClause, _InsertColumnsClause},
+}
+
+// requiredClauses must show up at least once in a statement of each kind. Each
+// entry lists clauses that satisfy the requirement, any one of them is enough.
+var requiredClauses = map[StatementKind][][]ClauseKind{
+	_UpdateStatement: {{_SetClause}},
+	_InsertStatement: {{_ValuesClause, _DefaultValuesClause}},
+}
+
+// Validate checks that the statement makes sense before it's built. It
+// returns an error wrapping one of ErrInvalidClause, ErrDuplicateClause,
+// ErrEmptyClause or ErrMissingClause describing the first problem found.
+// Statements nested in it are validated too, such as common table
+// expressions, compound operands, subselects and the operands of Exists.
+//
+// A select without a from is valid since "select 1 + 1" is.
+func (s Statement) Validate() error {
+	if s.err != nil {
+		return s.err
+	}
+
+	if s.Kind == _unknownStatement {
+		return errors.New("sqlbuilder: unknown statement kind")
+	}
+
+	if err := s.validateNested(); err != nil {
+		return err
+	}
+
+	for _, cte := range s.with {
+		if n, ok := cte.sub.columnCount(); ok && len(cte.columns) > 0 && n != len(cte.columns) {
+			return fmt.Errorf("%w: common table expression %s has %d columns but selects %d",
+				ErrInvalidClause, cte.name, len(cte.columns), n)
+		}
+	}
+
+	if s.isCompound() {
+		return s.validateCompound()
+	}
+
+	if s.Kind == _ExplainStatement {
+		return s.validateExplain()
+	}
+
+	if len(s.Expressions) == 0 {
+		return fmt.Errorf("%w: %s statement has no expressions", ErrMissingClause, s.Kind)
+	}
+
+	counts := make(map[ClauseKind]int)
+
+	for _, clause := range s.Clauses {
+		kind := clause.Kind()
+
+		if !containsKind(allowedClauses[s.Kind], kind) && kind != _CommentClause {
+			return fmt.Errorf("%w: %s in %s statement", ErrInvalidClause, kind, s.Kind)
+		}
+
+		if isEmptyClause(clause) {
+			return fmt.Errorf("%w: %s in %s statement", ErrEmptyClause, kind, s.Kind)
+		}
+
+		counts[kind]++
+	}
+
+	for _, kind := range singleClauses {
+		if counts[kind] > 1 {
+			return fmt.Errorf("%w: %s used %d times in %s statement", ErrDuplicateClause, kind, counts[kind], s.Kind)
+		}
+	}
+
+	for _, pair := range conflictingClauses {
+		if counts[pair[0]] > 0 && counts[pair[1]] > 0 {
+			return fmt.Errorf("%w: %s with %s in %s statement", ErrInvalidClause, pair[0], pair[1], s.Kind)
+		}
+	}
+
+	if err := s.validateDistinctOn(); err != nil {
+		return err
+	}
+
+	for _, kinds := range requiredClauses[s.Kind] {
+		if !hasAnyKind(counts, kinds) {
+			names := make([]string, len(kinds))
+
+			for i, kind := range kinds {
+				names[i] = kind.String()
+			}
+
+			return fmt.Errorf("%w: %s statement requires %s", ErrMissingClause, s.Kind, strings.Join(names, " or "))
+		}
+	}
+
+	return nil
+}
+
+// validateDistinctOn checks that the order by starts with the distinct on
+// columns, in any order. Expressions are compared by how they build, ignoring
+// sort directions.
+func (s Statement) validateDistinctOn() error {
+	if len(s.distinctOn) == 0 || s.distinctOnUnchecked {
+		return nil
+	}
+
+	var order []Expression
+
+	for _, clause := range s.Clauses {
+		if c, ok := clause.(orderByClause); ok {
+			order = append(order, c.columns...)
+		}
+	}
+
+	if len(order) < len(s.distinctOn) {
+		return fmt.Errorf("%w: distinct on needs an order by starting with its columns", ErrMissingClause)
+	}
+
+	leading := make(map[string]bool, len(s.distinctOn))
+
+	for _, expr := range order[:len(s.distinctOn)] {
+		leading[sortKey(expr)] = true
+	}
+
+	for _, expr := range s.distinctOn {
+		if key := sortKey(expr); !leading[key] {
+			return fmt.Errorf("%w: order by doesn't start with distinct on column %s", ErrInvalidClause, key)
+		}
+	}
+
+	return nil
+}
+
+// sortKey builds expr without any sort direction or nulls ordering.
+func sortKey(expr Expression) string {
+	key := expr.Build()
+
+	for _, suffix := range []string{" nulls first", " nulls last", " asc", " desc"} {
+		key = strings.TrimSuffix(key, suffix)
+	}
+
+	return key
+}
+
+func (s Statement) validateCompound() error {
+	if len(s.Expressions) < 2 {
+		return fmt.Errorf("%w: %s needs at least two statements", ErrMissingClause, s.Kind)
+	}
+
+	for _, clause := range s.Clauses {
+		switch clause.Kind() {
+		case _OrderByClause, _LimitClause, _OffsetClause, _CommentClause:
+		default:
+			return fmt.Errorf("%w: %s in %s statement", ErrInvalidClause, clause.Kind(), s.Kind)
+		}
+	}
+
+	return nil
+}
+
+func (s Statement) validateExplain() error {
+	if len(s.Clauses) > 0 {
+		return fmt.Errorf("%w: %s in %s statement", ErrInvalidClause, s.Clauses[0].Kind(), s.Kind)
+	}
+
+	if _, ok := s.Expressions[len(s.Expressions)-1].(Statement); !ok {
+		return fmt.Errorf("%w: %s statement has no statement to explain", ErrMissingClause, s.Kind)
+	}
+
+	return nil
+}
+
+// validateNested validates every statement nested in s, wherever it's used.
+// A nested statement with an error builds as "", so without this the error
+// would be lost.
+func (s Statement) validateNested() error {
+	var err error
+
+	for _, child := range s.children() {
+		walk(child, func(expr Expression) bool {
+			switch e := expr.(type) {
+			case Statement:
+				if err == nil {
+					err = e.Validate()
+				}
+
+				return false
+			case CompoundStatement:
+				if err == nil {
+					err = e.Validate()
+				}
+
+				return false
+			}
+
+			return err == nil
+		})
+	}
+
+	return err
+}
+
+// columnCount returns the number of columns the statement selects, when it's
+// known. It's only known for selects of Columns, or compounds of them, since a
+// single expression such as Ref("*") can select any number of columns.
+func (s Statement) columnCount() (int, bool) {
+	if s.isCompound() {
+		if sub, ok := s.Expressions[0].(Statement); ok {
+			return sub.columnCount()
+		}
+
+		return 0, false
+	}
+
+	if s.Kind != _SelectStatement || len(s.Expressions) != 1 {
+		return 0, false
+	}
+
+	if me, ok := s.Expressions[0].(MultiExpression); ok {
+		return len(me.Expressions), true
+	}
+
+	return 0, false
+}
+
+// BuildChecked validates the statement and builds it if it's valid.
+func (s Statement) BuildChecked() (string, error) {
+	if err := s.Validate(); err != nil {
+		return "", err
+	}
+
+	return s.Build(), nil
+}
+
+// BuildArgsChecked is like BuildArgs but validates the statement first and
+// checks its args. An error wrapping ErrArgCount is returned when the
+// statement mixes Placeholder with Arg, since the args returned wouldn't line
+// up with the placeholders. An error wrapping ErrNilArg is returned when Arg
+// binds nil to the right side of a comparison like Equals or In in a where,
+// having or join predicate, which never matches; use IsNull there instead.
+func (s Statement) BuildArgsChecked() (string, []interface{}, error) {
+	if err := s.Validate(); err != nil {
+		return "", nil, err
+	}
+
+	ctx := &BuildContext{}
+	sql := s.BuildWith(ctx)
+
+	if n := len(ctx.args); n > 0 && n != len(ctx.params) {
+		return "", nil, fmt.Errorf("%w: %d bound args for %d placeholders", ErrArgCount, n, len(ctx.params))
+	}
+
+	if pred, ok := nilComparison(s, false); ok {
+		return "", nil, fmt.Errorf("%w: %s", ErrNilArg, pred.Build())
+	}
+
+	return sql, ctx.Args(), nil
+}
+
+// nilComparisonOps are the operators that are never true when their right side
+// is null.
+var nilComparisonOps = map[string]bool{
+	"=": true, "<>": true, "<": true, ">": true, "<=": true, ">=": true, "in": true, "like": true,
+}
+
+// nilComparison finds the first comparison in a where, having or join
+// predicate of expr with nil bound by Arg directly to its right side.
+// inPredicate is whether expr is in one of those predicates.
+func nilComparison(expr Expression, inPredicate bool) (PredicateExpr, bool) {
+	switch e := expr.(type) {
+	case Statement, CompoundStatement:
+		inPredicate = false
+	case Clause:
+		kind := e.Kind()
+		inPredicate = kind == _WhereClause || kind == _HavingClause || isJoin(kind)
+	case PredicateExpr:
+		if arg, ok := e.Right.(ArgExpr); ok && inPredicate && arg.Value == nil && nilComparisonOps[e.Op] {
+			return e, true
+		}
+	}
+
+	for _, child := range children(expr) {
+		if isNilExpression(child) {
+			continue
+		}
+
+		if pred, ok := nilComparison(child, inPredicate); ok {
+			return pred, true
+		}
+	}
+
+	return PredicateExpr{}, false
+}
+
+func hasAnyKind(counts map[ClauseKind]int, kinds []ClauseKind) bool {
+	for _, kind := range kinds {
+		if counts[kind] > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsKind(kinds []ClauseKind, kind ClauseKind) bool {
+	for _, k := range kinds {
+		if k == kind {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isEmptyClause(clause Clause) bool {
+	switch c := clause.(type) {
+	case whereClause:
+		return len(c.predicates.Expressions) == 0
+	case havingClause:
+		return len(c.predicates.Expressions) == 0
+	case setClause:
+		return len(c.assignments) == 0
+	case valuesClause:
+		return len(c.values) == 0
+	case insertColumnsClause:
+		return len(c.columns) == 0
+	case fromClause:
+		return len(c.tables) == 0
+	case orderByClause:
+		return len(c.columns) == 0
+	case groupByClause:
+		return len(c.columns) == 0 && len(c.sets) == 0
+	case returningClause:
+		return len(c.columns) == 0
+	}
+
+	return false
+}
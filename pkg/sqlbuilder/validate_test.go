@@ -0,0 +1,521 @@
+package sqlbuilder
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestValidate(t *testing.T) {
+	valid := Select(Ref("id"), From(Ref("items")))
+
+	cases := []struct {
+		description string
+		err         error
+		statement   Statement
+	}{
+		{
+			description: "valid select",
+			statement:   Select(Ref("*"), From(Ref("items")), Where(Equals(Ref("id"), Placeholder())), OrderBy("id"), Limit(1)),
+		},
+		{
+			description: "select without from",
+			statement:   Select(Ref("1 + 1")),
+		},
+		{
+			description: "empty where is left out",
+			statement:   Select(Ref("*"), From(Ref("items")), Where()),
+		},
+		{
+			description: "empty group by",
+			err:         ErrEmptyClause,
+			statement:   Select(Ref("*"), From(Ref("items")), GroupBy()),
+		},
+		{
+			description: "locking on update",
+			err:         ErrInvalidClause,
+			statement:   Update(Ref("items"), Set(Equals(Ref("a"), Placeholder())), ForUpdate()),
+		},
+		{
+			description: "order by on insert",
+			err:         ErrInvalidClause,
+			statement:   Insert(Ref("items"), Values(Placeholder()), OrderBy("id")),
+		},
+		{
+			description: "set on select",
+			err:         ErrInvalidClause,
+			statement:   Select(Ref("*"), From(Ref("items")), Set(Equals(Ref("a"), Placeholder()))),
+		},
+		{
+			description: "where on compound",
+			err:         ErrInvalidClause,
+			statement:   Union(valid, valid, Where(Equals(Ref("id"), Placeholder()))),
+		},
+		{
+			description: "order by on compound",
+			statement:   Union(valid, valid, OrderBy("id"), Limit(10)),
+		},
+		{
+			description: "invalid compound operand",
+			err:         ErrEmptyClause,
+			statement:   Union(valid, Select(Ref("*"), From())),
+		},
+		{
+			description: "invalid common table expression",
+			err:         ErrMissingClause,
+			statement:   Select(Ref("*"), From(Ref("t")), With("t", Insert(Ref("items")))),
+		},
+		{
+			description: "duplicate limit",
+			err:         ErrDuplicateClause,
+			statement: Statement{
+				Kind:        _SelectStatement,
+				Expressions: []Expression{Ref("*")},
+				Clauses:     []Clause{limitClause{count: Ref("1")}, limitClause{count: Ref("2")}},
+			},
+		},
+		{
+			description: "update without set",
+			err:         ErrMissingClause,
+			statement:   Update(Ref("items"), Set(), Where(Bool("a"))),
+		},
+		{
+			description: "insert without values",
+			err:         ErrMissingClause,
+			statement:   Insert(Ref("items"), InsertColumns("id")),
+		},
+		{
+			description: "valid insert",
+			statement:   Insert(Ref("items"), InsertColumns("id"), Values(Placeholder()), Returning(Ref("id"))),
+		},
+		{
+			description: "valid default values",
+			statement:   Insert(Ref("items"), DefaultValues(), Returning(Ref("id"))),
+		},
+		{
+			description: "default values with values",
+			err:         ErrInvalidClause,
+			statement:   Insert(Ref("items"), DefaultValues(), Values(Placeholder())),
+		},
+		{
+			description: "default values with columns",
+			err:         ErrInvalidClause,
+			statement:   Insert(Ref("items"), InsertColumns("id"), DefaultValues()),
+		},
+		{
+			description: "cte columns match",
+			statement:   Select(Ref("*"), From(Ref("t")), With("t", Select(Columns(Ref("a"), Ref("b"))), WithColumns("x", "y"))),
+		},
+		{
+			description: "cte columns with unknown count",
+			statement:   Select(Ref("*"), From(Ref("t")), With("t", Select(Ref("*"), From(Ref("u"))), WithColumns("x", "y"))),
+		},
+		{
+			description: "cte columns mismatch",
+			err:         ErrInvalidClause,
+			statement:   Select(Ref("*"), From(Ref("t")), With("t", Select(Columns(Ref("a"), Ref("b"), Ref("c"))), WithColumns("x", "y"))),
+		},
+		{
+			description: "compound cte columns mismatch",
+			err:         ErrInvalidClause,
+			statement: Select(Ref("*"), From(Ref("t")), With("t", Union(
+				Select(Columns(Ref("a"), Ref("b"))),
+				Select(Columns(Ref("c"), Ref("d"))),
+			), WithColumns("x"))),
+		},
+		{
+			description: "valid delete",
+			statement:   Delete(Ref("items"), Where(Equals(Ref("id"), Placeholder()))),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			err := c.statement.Validate()
+			if c.err == nil {
+				is.NoErr(err)
+				return
+			}
+
+			is.True(errors.Is(err, c.err))
+
+			sql, err := c.statement.BuildChecked()
+			is.True(errors.Is(err, c.err))
+			is.Equal("", sql)
+		})
+	}
+}
+
+func TestBuildChecked(t *testing.T) {
+	is := is.New(t)
+
+	st := Select(Ref("*"), From(Ref("items")), Where(Equals(Ref("id"), Placeholder())))
+	sql, err := st.BuildChecked()
+
+	is.NoErr(err)
+	is.Equal(st.Build(), sql)
+}
+
+func TestErr(t *testing.T) {
+	var nilFunc ContextExpressionFunc
+
+	cases := []struct {
+		description string
+		err         error
+		statement   Statement
+	}{
+		{
+			description: "no error",
+			statement:   Select(Ref("*"), From(Ref("items")), Where(Equals(Ref("id"), Placeholder()))),
+		},
+		{
+			description: "nil where",
+			err:         ErrNilExpression,
+			statement:   Select(Ref("*"), From(Ref("items")), Where(nil)),
+		},
+		{
+			description: "nil expression func",
+			err:         ErrNilExpression,
+			statement:   Select(Ref("*"), From(Ref("items")), Where(Equals(Ref("a"), Placeholder()), nilFunc)),
+		},
+		{
+			description: "nil join table",
+			err:         ErrNilExpression,
+			statement:   Select(Ref("*"), From(Ref("items")), LeftJoin(nil, Equals(Ref("a"), Ref("b")))),
+		},
+		{
+			description: "nil value",
+			err:         ErrNilExpression,
+			statement:   Insert(Ref("items"), Values(Placeholder(), nil)),
+		},
+		{
+			description: "nil set",
+			err:         ErrNilExpression,
+			statement:   Update(Ref("items"), Set(Equals(Ref("a"), Placeholder()), nil)),
+		},
+		{
+			description: "nil nested in predicate",
+			err:         ErrNilExpression,
+			statement:   Select(Ref("*"), From(Ref("items")), Where(Equals(Ref("a"), Func("lower", nil)))),
+		},
+		{
+			description: "nil column",
+			err:         ErrNilExpression,
+			statement:   Select(Columns(Ref("a"), nil), From(Ref("items"))),
+		},
+		{
+			description: "nil from func",
+			err:         ErrNilExpression,
+			statement:   Select(Ref("*"), FromFunc(nil, "t")),
+		},
+		{
+			description: "nil join using table",
+			err:         ErrNilExpression,
+			statement:   Select(Ref("*"), From(Ref("items")), JoinUsing(nil, "a")),
+		},
+		{
+			description: "nil update table",
+			err:         ErrNilExpression,
+			statement:   Update(nil, Set(Equals(Ref("a"), Placeholder()))),
+		},
+		{
+			description: "nil insert table",
+			err:         ErrNilExpression,
+			statement:   Insert(nil, Values(Placeholder())),
+		},
+		{
+			description: "nil delete table",
+			err:         ErrNilExpression,
+			statement:   Delete(nil, Where(Equals(Ref("a"), Placeholder()))),
+		},
+		{
+			description: "nil truncate table",
+			err:         ErrNilExpression,
+			statement:   Truncate(nil),
+		},
+		{
+			description: "is null",
+			statement:   Select(Ref("*"), From(Ref("items")), Where(IsNull(Ref("a")))),
+		},
+		{
+			description: "searched case",
+			statement:   Select(Case().When(Bool("a"), Const("x")), From(Ref("items"))),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			err := c.statement.Err()
+			if c.err == nil {
+				is.NoErr(err)
+				return
+			}
+
+			is.True(errors.Is(err, c.err))
+			is.True(errors.Is(c.statement.Validate(), c.err))
+			is.Equal("", c.statement.Build())
+
+			sql, args := c.statement.BuildArgs()
+			is.Equal("", sql)
+			is.Equal(0, len(args))
+		})
+	}
+}
+
+func TestNestedErr(t *testing.T) {
+	bad := Select(Ref("id"), From(Ref("bans")), Where(nil))
+
+	cases := []struct {
+		description string
+		statement   Statement
+	}{
+		{
+			description: "from subselect",
+			statement:   Select(Ref("*"), FromSubselect(bad, "x")),
+		},
+		{
+			description: "from subselect with columns",
+			statement:   Select(Ref("*"), FromSubselect(bad, "x", "id")),
+		},
+		{
+			description: "join subselect",
+			statement:   Select(Ref("*"), From(Ref("users u")), JoinSubselect(bad, "b", Equals(Ref("b.id"), Ref("u.id")))),
+		},
+		{
+			description: "join lateral",
+			statement:   Select(Ref("*"), From(Ref("users u")), JoinLateral(bad, "b")),
+		},
+		{
+			description: "exists",
+			statement:   Select(Ref("*"), From(Ref("users")), Where(Exists(bad))),
+		},
+		{
+			description: "in subquery",
+			statement:   Select(Ref("*"), From(Ref("users")), Where(InSubquery(Ref("id"), bad))),
+		},
+		{
+			description: "not in subquery",
+			statement:   Select(Ref("*"), From(Ref("users")), Where(NotInSubquery(Ref("id"), bad))),
+		},
+		{
+			description: "scalar subquery",
+			statement:   Select(Columns(Ref("id"), As(ScalarSubquery(bad), "banned")), From(Ref("users"))),
+		},
+		{
+			description: "compound in subselect",
+			statement:   Select(Ref("*"), FromSubselect(Union(bad, bad), "x")),
+		},
+		{
+			description: "scalar subquery in predicate",
+			statement:   Select(Ref("*"), From(Ref("users")), Where(Equals(Ref("a"), ScalarSubquery(bad)))),
+		},
+		{
+			description: "scalar subquery in any array",
+			statement:   Select(Ref("*"), From(Ref("users")), Where(AnyArray(Ref("a"), ScalarSubquery(bad)))),
+		},
+		{
+			description: "with",
+			statement:   Select(Ref("*"), From(Ref("b")), With("b", bad)),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			is.True(errors.Is(c.statement.Err(), ErrNilExpression))
+
+			sql, err := c.statement.BuildChecked()
+
+			is.True(errors.Is(err, ErrNilExpression))
+			is.Equal("", sql)
+		})
+	}
+}
+
+func TestErrKeepsFirst(t *testing.T) {
+	is := is.New(t)
+
+	st := Select(Ref("*"), From(Ref("items")), Where(nil), OrderByExpr(nil))
+
+	is.True(strings.Contains(st.Err().Error(), "where"))
+}
+
+func TestBuildArgsChecked(t *testing.T) {
+	cases := []struct {
+		description string
+		expected    string
+		args        []interface{}
+		err         error
+		statement   Statement
+	}{
+		{
+			description: "bound args",
+			expected:    "select * from items where (id = ? and kind = ?)",
+			args:        []interface{}{1, "a"},
+			statement:   Select(Ref("*"), From(Ref("items")), Where(Equals(Ref("id"), Arg(1)), Equals(Ref("kind"), Arg("a")))),
+		},
+		{
+			description: "bare placeholders",
+			expected:    "select * from items where (id = ? and kind = ?)",
+			statement:   Select(Ref("*"), From(Ref("items")), Where(Equals(Ref("id"), Placeholder()), Equals(Ref("kind"), Placeholder()))),
+		},
+		{
+			description: "mixed placeholders",
+			err:         ErrArgCount,
+			statement:   Select(Ref("*"), From(Ref("items")), Where(Equals(Ref("id"), Arg(1)), Equals(Ref("kind"), Placeholder()))),
+		},
+		{
+			description: "mixed placeholders in subquery",
+			err:         ErrArgCount,
+			statement: Select(Ref("*"), From(Ref("items")), Where(
+				Equals(Ref("id"), Arg(1)),
+				Exists(Select(Ref("1"), From(Ref("tags")), Where(Equals(Ref("name"), Placeholder())))),
+			)),
+		},
+		{
+			description: "nil in where",
+			err:         ErrNilArg,
+			statement:   Select(Ref("*"), From(Ref("items")), Where(Equals(Ref("deleted_at"), Arg(nil)))),
+		},
+		{
+			description: "nil in join",
+			err:         ErrNilArg,
+			statement:   Select(Ref("*"), From(Ref("items")), Join(Ref("tags"), Equals(Ref("tags.name"), Arg(nil)))),
+		},
+		{
+			description: "nil in left join",
+			err:         ErrNilArg,
+			statement:   Select(Ref("*"), From(Ref("items")), LeftJoin(Ref("tags"), Equals(Ref("tags.name"), Arg(nil)))),
+		},
+		{
+			description: "nil in having",
+			err:         ErrNilArg,
+			statement:   Select(Ref("kind"), From(Ref("items")), GroupBy("kind"), Having(Greater(Max(Ref("price")), Arg(nil)))),
+		},
+		{
+			description: "nil in nested where",
+			err:         ErrNilArg,
+			statement: Update(Ref("items"), Set(Equals(Ref("tag_id"),
+				ScalarSubquery(Select(Ref("id"), From(Ref("tags")), Where(Equals(Ref("name"), Arg(nil))))),
+			))),
+		},
+		{
+			description: "nil is distinct from",
+			expected:    "select * from items where (deleted_at is distinct from ?)",
+			args:        []interface{}{nil},
+			statement:   Select(Ref("*"), From(Ref("items")), Where(IsDistinctFrom(Ref("deleted_at"), Arg(nil)))),
+		},
+		{
+			description: "nil is not distinct from",
+			expected:    "select * from items where (deleted_at is not distinct from ?)",
+			args:        []interface{}{nil},
+			statement:   Select(Ref("*"), From(Ref("items")), Where(IsNotDistinctFrom(Ref("deleted_at"), Arg(nil)))),
+		},
+		{
+			description: "nil in coalesce",
+			expected:    "select * from items where (coalesce(price, ?) > ?)",
+			args:        []interface{}{nil, 10},
+			statement:   Select(Ref("*"), From(Ref("items")), Where(Greater(Coalesce(Ref("price"), Arg(nil)), Arg(10)))),
+		},
+		{
+			description: "nil in values",
+			expected:    "insert into items (id, deleted_at) values (?, ?)",
+			args:        []interface{}{1, nil},
+			statement:   Insert(Ref("items"), InsertColumns("id", "deleted_at"), Values(Arg(1), Arg(nil))),
+		},
+		{
+			description: "nil in set",
+			expected:    "update items set deleted_at = ? where (id = ?)",
+			args:        []interface{}{nil, 1},
+			statement:   Update(Ref("items"), Set(Equals(Ref("deleted_at"), Arg(nil))), Where(Equals(Ref("id"), Arg(1)))),
+		},
+		{
+			description: "nil in subquery columns",
+			expected:    "select * from items where (id in (select ? from tags))",
+			args:        []interface{}{nil},
+			statement:   Select(Ref("*"), From(Ref("items")), Where(InSubquery(Ref("id"), Select(Arg(nil), From(Ref("tags")))))),
+		},
+		{
+			description: "invalid statement",
+			err:         ErrEmptyClause,
+			statement:   Select(Ref("*"), From(Ref("items")), GroupBy()),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			sql, args, err := c.statement.BuildArgsChecked()
+			if c.err != nil {
+				is.True(errors.Is(err, c.err))
+				is.Equal("", sql)
+				is.Equal(0, len(args))
+				return
+			}
+
+			is.NoErr(err)
+			is.Equal(c.expected, sql)
+			is.Equal(c.args, args)
+		})
+	}
+}
+
+func TestValidateDistinctOn(t *testing.T) {
+	cases := []struct {
+		description string
+		err         error
+		statement   Statement
+	}{
+		{
+			description: "matching order by",
+			statement: Select(Ref("*"), From(Ref("events")), DistinctOn(Ref("user_id"), Ref("kind")),
+				OrderBy("kind", "user_id", "created_at desc")),
+		},
+		{
+			description: "sort directions",
+			statement: Select(Ref("*"), From(Ref("events")), DistinctOn(Ref("user_id")),
+				OrderByExpr(NullsLast(Desc(Ref("user_id"))), Ref("created_at"))),
+		},
+		{
+			description: "expression",
+			statement: Select(Ref("*"), From(Ref("events")), DistinctOn(Func("lower", Ref("kind"))),
+				OrderByExpr(Func("lower", Ref("kind")))),
+		},
+		{
+			description: "no order by",
+			err:         ErrMissingClause,
+			statement:   Select(Ref("*"), From(Ref("events")), DistinctOn(Ref("user_id"))),
+		},
+		{
+			description: "wrong leading column",
+			err:         ErrInvalidClause,
+			statement:   Select(Ref("*"), From(Ref("events")), DistinctOn(Ref("user_id")), OrderBy("created_at", "user_id")),
+		},
+		{
+			description: "too few order by columns",
+			err:         ErrMissingClause,
+			statement:   Select(Ref("*"), From(Ref("events")), DistinctOn(Ref("user_id"), Ref("kind")), OrderBy("user_id")),
+		},
+		{
+			description: "unchecked",
+			statement:   Select(Ref("*"), From(Ref("events")), UncheckedDistinctOn(Ref("user_id")), OrderBy("created_at")),
+		},
+	}
+
+	is := is.New(t)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			err := c.statement.Validate()
+			if c.err == nil {
+				is.NoErr(err)
+				return
+			}
+
+			is.True(errors.Is(err, c.err))
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package sqlbuilder
+
+// ParentExpression is an Expression made of other expressions. Walk visits the
+// children of expressions that implement it, so custom expressions should
+// implement it to be walked into.
+type ParentExpression interface {
+	Expression
+	Children() []Expression
+}
+
+// Walk calls fn for st and then, depth first, for every expression in it:
+// common table expressions, columns, clauses and the expressions inside them,
+// including nested statements. When fn returns false the children of that
+// expression are skipped.
+//
+// The expressions built by this package are nodes Walk can descend into and fn
+// can tell apart with a type switch, like RefExpr for Ref, PlaceholderExpr and
+// ArgExpr for Placeholder and Arg, RawExpr for Raw and PredicateExpr for
+// Equals. Expressions whose sql depends on the dialect, like Concat and
+// Window, are walked into too. Expressions built from an ExpressionFunc or
+// ContextExpressionFunc are opaque. They're passed to fn but their children
+// can't be walked into.
+func Walk(st Statement, fn func(Expression) bool) {
+	walk(st, fn)
+}
+
+func walk(expr Expression, fn func(Expression) bool) {
+	if isNilExpression(expr) || !fn(expr) {
+		return
+	}
+
+	for _, child := range children(expr) {
+		walk(child, fn)
+	}
+}
+
+// children returns the expressions expr is made of, in the order they're
+// built.
+func children(expr Expression) []Expression {
+	switch e := expr.(type) {
+	case ParentExpression:
+		return e.Children()
+	case Statement:
+		return e.children()
+	case CompoundStatement:
+		return e.children()
+	case MultiExpression:
+		return e.Expressions
+	case CaseExpression:
+		exprs := []Expression{e.operand}
+
+		for _, w := range e.whens {
+			exprs = append(exprs, w.condition, w.result)
+		}
+
+		return append(exprs, e.els)
+	case fromClause:
+		return e.tables
+	case joinClause:
+		return append([]Expression{e.table}, e.predicates...)
+	case valuesClause:
+		return e.values
+	case setClause:
+		return e.assignments
+	case onConflictClause:
+		return e.update
+	case whereClause:
+		return []Expression{e.predicates}
+	case havingClause:
+		return []Expression{e.predicates}
+	case groupByClause:
+		return e.columns
+	case windowClause:
+		return []Expression{e.spec}
+	case orderByClause:
+		return e.columns
+	case limitClause:
+		return []Expression{e.count}
+	case offsetClause:
+		return []Expression{e.start}
+	case returningClause:
+		return e.columns
+	}
+
+	return nil
+}
+
+func (s Statement) children() []Expression {
+	var exprs []Expression
+
+	for _, cte := range s.with {
+		exprs = append(exprs, cte.sub)
+	}
+
+	exprs = append(exprs, s.distinctOn...)
+	exprs = append(exprs, s.Expressions...)
+
+	for _, clause := range s.Clauses {
+		exprs = append(exprs, clause)
+	}
+
+	return exprs
+}
@@ -0,0 +1,159 @@
+package sqlbuilder
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestWalk(t *testing.T) {
+	is := is.New(t)
+
+	recent := Select(Ref("id"), From(Ref("orders")), Where(Greater(Ref("created_at"), Placeholder())))
+	st := Select(Columns(Ref("u.id"), Ref("u.name")), From(Ref("users u")),
+		With("recent", recent),
+		Join(Ref("recent r"), Equals(Ref("r.user_id"), Ref("u.id"))),
+		Where(Equals(Ref("u.team"), Placeholder()), IsNull(Ref("u.deleted_at"))),
+		OrderBy("u.name"),
+		Limit(10),
+	)
+
+	var (
+		statements int
+		tables     []string
+		predicates []string
+	)
+
+	Walk(st, func(expr Expression) bool {
+		switch e := expr.(type) {
+		case Statement:
+			statements++
+		case Clause:
+			switch e.Kind() {
+			case _FromClause:
+				for _, table := range children(e) {
+					tables = append(tables, table.Build())
+				}
+			case _WhereClause:
+				for _, pred := range children(children(e)[0]) {
+					predicates = append(predicates, pred.Build())
+				}
+			}
+		}
+
+		return true
+	})
+
+	is.Equal(2, statements)
+	is.Equal([]string{"orders", "users u"}, tables)
+	is.Equal([]string{"created_at > ?", "u.team = ?", "u.deleted_at is null"}, predicates)
+}
+
+func TestWalkLeaves(t *testing.T) {
+	is := is.New(t)
+
+	banned := Select(Ref("user_id"), From(Ref("bans")), Where(Greater(Ref("until"), Arg("2024-01-01"))))
+	st := Select(Columns(Ref("id"), Ref("name")), From(Ref("users")),
+		Where(
+			Equals(Ref("team"), Placeholder()),
+			NotInSubquery(Ref("id"), banned),
+			RawArgs("age > ?", 18),
+			Equals(Ref("role"), NamedArg("role", "admin")),
+		),
+	)
+
+	var (
+		placeholders int
+		args         []interface{}
+		refs         []string
+	)
+
+	Walk(st, func(expr Expression) bool {
+		switch e := expr.(type) {
+		case PlaceholderExpr:
+			placeholders++
+		case ArgExpr:
+			placeholders++
+			args = append(args, e.Value)
+		case RawExpr:
+			placeholders += len(e.Args)
+			args = append(args, e.Args...)
+		case RefExpr:
+			refs = append(refs, e.Name)
+		}
+
+		return true
+	})
+
+	is.Equal(4, placeholders)
+	is.Equal([]interface{}{"2024-01-01", 18, "admin"}, args)
+	is.Equal([]string{"id", "name", "users", "team", "id", "user_id", "bans", "until", "role"}, refs)
+}
+
+func TestWalkSkip(t *testing.T) {
+	is := is.New(t)
+
+	st := Union(
+		Select(Ref("id"), From(Ref("a")), Where(Bool("x"))),
+		Select(Ref("id"), From(Ref("b")), Where(Bool("y"))),
+	)
+
+	var visited, clauses int
+
+	Walk(st, func(expr Expression) bool {
+		visited++
+
+		if _, ok := expr.(Clause); ok {
+			clauses++
+			return false
+		}
+
+		return true
+	})
+
+	// the union, two operands, each with an id column and from and where
+	// clauses whose children are skipped
+	is.Equal(9, visited)
+	is.Equal(4, clauses)
+}
+
+func TestWalkCase(t *testing.T) {
+	is := is.New(t)
+
+	st := Select(Case().When(Bool("a"), Const("x")).Else(Const("y")), From(Ref("t")))
+
+	var n int
+
+	Walk(st, func(expr Expression) bool {
+		n++
+		return true
+	})
+
+	// the select, the case and its three branches, the from clause and its
+	// table
+	is.Equal(7, n)
+}
+
+func TestWalkDialectExpressions(t *testing.T) {
+	is := is.New(t)
+
+	st := Select(As(Concat(Ref("first"), Placeholder()), "name"), From(Ref("users")),
+		Where(
+			AnyArray(Ref("id"), Array(Placeholder(), Placeholder())),
+			Matches(Ref("email"), Placeholder()),
+			Equals(Neg(Ref("balance")), BitXor(Ref("a"), Placeholder())),
+		),
+	)
+
+	var placeholders int
+
+	Walk(st, func(expr Expression) bool {
+		if _, ok := expr.(PlaceholderExpr); ok {
+			placeholders++
+		}
+
+		return true
+	})
+
+	is.Equal(5, placeholders)
+}